@@ -2,6 +2,7 @@ package bilibili_go
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -18,32 +19,25 @@ import (
 func (c *Client) qrcodeGenerate() (*QrcodeGenerateResponse, error) {
 	uri := "https://passport.bilibili.com/x/passport-login/web/qrcode/generate"
 
-	var baseResp BaseResponse
-	err := c.getHttpClient(false).Get(uri).EndStruct(&baseResp)
+	var resp Response[QrcodeGenerateResponse]
+	err := c.getHttpClient(false).Get(uri).EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &QrcodeGenerateResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // 查询二维码扫描状态 https://passport.bilibili.com/x/passport-login/web/qrcode/poll
 func (c *Client) qrcodePoll(qrcodeKey string) (*QrcodePollResponse, []*http.Cookie, error) {
 	uri := "https://passport.bilibili.com/x/passport-login/web/qrcode/poll"
 
-	var baseResp BaseResponse
+	var resp Response[QrcodePollResponse]
 	var cookies []*http.Cookie
 
 	err := c.getHttpClient(false).Get(uri).
 		AddParams("qrcode_key", qrcodeKey).
-		EndStruct(&baseResp, func(response *http.Response) error {
+		EndStruct(&resp, func(response *http.Response) error {
 			cookies = response.Cookies()
 
 			return nil
@@ -51,86 +45,58 @@ func (c *Client) qrcodePoll(qrcodeKey string) (*QrcodePollResponse, []*http.Cook
 	if err != nil {
 		return nil, nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &QrcodePollResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, cookies, err
+	return &resp.Data, cookies, nil
 }
 
 // GetMyAccount 获取个人账号信息 https://api.bilibili.com/x/member/web/account
 func (c *Client) GetMyAccount() (*AccountResponse, error) {
 	uri := "https://api.bilibili.com/x/member/web/account"
 
-	var baseResp BaseResponse
-	err := c.getHttpClient(true).Get(uri).EndStruct(&baseResp)
+	var resp Response[AccountResponse]
+	err := c.getHttpClient(true).Get(uri).EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &AccountResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetNavigation 获取导航栏信息（个人详细信息） https://api.bilibili.com/x/web-interface/nav
 func (c *Client) GetNavigation() (*NavigationResponse, error) {
 	uri := "https://api.bilibili.com/x/web-interface/nav"
 
-	var baseResp BaseResponse
-	err := c.getHttpClient(true).Get(uri).EndStruct(&baseResp)
+	var resp Response[NavigationResponse]
+	err := c.getHttpClient(true).Get(uri).EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &NavigationResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetNavigationStatus 获取导航栏状态（粉丝数信息）https://api.bilibili.com/x/web-interface/nav/stat
 func (c *Client) GetNavigationStatus() (*NavigationStatusResponse, error) {
 	uri := "https://api.bilibili.com/x/web-interface/nav/stat"
 
-	var baseResp BaseResponse
-	err := c.getHttpClient(true).Get(uri).EndStruct(&baseResp)
+	var resp Response[NavigationStatusResponse]
+	err := c.getHttpClient(true).Get(uri).EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &NavigationStatusResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // 视频预上传 https://member.bilibili.com/preupload
-func (c *Client) preUpload(filename string, size int64) (*PreUploadResponse, error) {
+func (c *Client) preUpload(ctx context.Context, filename string, size int64, line UploadLine) (*PreUploadResponse, error) {
 	uri := "https://member.bilibili.com/preupload"
 
 	var resp PreUploadResponse
 
-	err := c.getHttpClient(true).Get(uri).
-		AddParams("zone", "cs").
-		AddParams("upcdn", "bldsa").
+	err := c.getHttpClient(true).SetContext(ctx).Get(uri).
+		AddParams("zone", line.Zone).
+		AddParams("upcdn", line.Upcdn).
 		AddParams("probe_version", "20221109").
 		AddParams("name", filename).
 		AddParams("r", "upos").
@@ -147,17 +113,17 @@ func (c *Client) preUpload(filename string, size int64) (*PreUploadResponse, err
 	return &resp, err
 }
 
-// 获取上传id https://upos-cs-upcdnbldsa.bilivideo.com
-func (c *Client) getUploadID(uri string, auth string, bizID int, size int64) (*GetUploadIDResponse, error) {
+// 获取上传id https://upos-cs-upcdn{line}.bilivideo.com
+func (c *Client) getUploadID(ctx context.Context, uri string, auth string, bizID int, size int64, chunkSize int64) (*GetUploadIDResponse, error) {
 	var resp GetUploadIDResponse
 
-	err := c.getHttpClient(true).Post(uri).
+	err := c.getHttpClient(true).SetContext(ctx).Post(uri).
 		SetHeader("X-Upos-Auth", auth).
 		AddParams("uploads", "").
 		AddParams("output", "json").
 		AddParams("profile", "ugcfx/bup").
 		AddParams("filesize", strconv.FormatInt(size, 10)).
-		AddParams("partsize", "10485760"). // 块大小：10mb
+		AddParams("partsize", strconv.FormatInt(chunkSize, 10)).
 		AddParams("meta_upos_uri", "upos://fxmetalf/n230829qn283p9ffyholy2gigl5advkd.txt").
 		AddParams("biz_id", strconv.Itoa(bizID)).
 		EndStruct(&resp)
@@ -170,34 +136,54 @@ func (c *Client) getUploadID(uri string, auth string, bizID int, size int64) (*G
 }
 
 // 分片上传文件
-func (c *Client) uploadFileClip(uri string, auth string, uploadId string, partNumber int, chunks int, size int, start int, end int, total int64, file []byte) error {
-	_, _, err := c.getHttpClient(true).Put(uri).
+func (c *Client) uploadFileClip(ctx context.Context, uri string, auth string, uploadId string, partNumber int, chunks int, size int, start int64, end int64, total int64, file []byte) (string, error) {
+	ctx = withSpanAttributes(ctx, Attr(AttrChunk, partNumber), Attr(AttrChunks, chunks), Attr(AttrUploadID, uploadId))
+
+	resp, body, err := c.getHttpClient(true).SetContext(ctx).Put(uri).
 		SetHeader("X-Upos-Auth", auth).
 		AddParams("partNumber", strconv.Itoa(partNumber)).
 		AddParams("uploadId", uploadId).
 		AddParams("chunk", strconv.Itoa(partNumber-1)).
 		AddParams("chunks", strconv.Itoa(chunks)).
 		AddParams("size", strconv.Itoa(size)).
-		AddParams("start", strconv.Itoa(start)).
-		AddParams("end", strconv.Itoa(end)).
+		AddParams("start", strconv.FormatInt(start, 10)).
+		AddParams("end", strconv.FormatInt(end, 10)).
 		AddParams("total", strconv.FormatInt(total, 10)).
 		SendBody(bytes.NewReader(file)).
 		End()
+	if err != nil {
+		return "", err
+	}
 
-	return err
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Header.Get("Etag"), nil
+	case http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+		// upload_id 不存在或者 auth 已过期
+		return "", fmt.Errorf("%w: %s %s", ErrUploadSessionExpired, resp.Status, body)
+	default:
+		return "", fmt.Errorf("unexpected status %s: %s", resp.Status, body)
+	}
 }
 
-// 上传完文件后调用该接口
-func (c *Client) uploadCheck(uri string, auth string, filename string, uploadID string, bizID int) (*UploadCheckResponse, error) {
+// 上传完文件后调用该接口，parts 为所有分片及其 etag
+func (c *Client) uploadCheck(ctx context.Context, uri string, auth string, filename string, uploadID string, bizID int, parts []UploadPart) (*UploadCheckResponse, error) {
 	var resp UploadCheckResponse
 
-	err := c.getHttpClient(true).Post(uri).
+	reqData, err := json.Marshal(map[string]any{"parts": parts})
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.getHttpClient(true).SetContext(ctx).Post(uri).
 		SetHeader("X-Upos-Auth", auth).
 		AddParams("output", "json").
 		AddParams("name", filename).
 		AddParams("profile", "ugcfx/bup").
 		AddParams("uploadId", uploadID).
 		AddParams("biz_id", strconv.Itoa(bizID)).
+		SetContentType("application/json;charset=UTF-8").
+		SendBody(bytes.NewReader(reqData)).
 		EndStruct(&resp)
 
 	if err != nil {
@@ -209,107 +195,88 @@ func (c *Client) uploadCheck(uri string, auth string, filename string, uploadID
 
 // UploadCover 上传封面 https://member.bilibili.com/x/vu/web/cover/up
 func (c *Client) UploadCover(imageData []byte) (*UploadCoverResponse, error) {
+	return c.uploadCover(context.Background(), imageData)
+}
+
+// uploadCover 同 UploadCover，ctx 用于链路追踪和取消
+func (c *Client) uploadCover(ctx context.Context, imageData []byte) (*UploadCoverResponse, error) {
 	uri := "https://member.bilibili.com/x/vu/web/cover/up"
 
 	base64Str := base64.StdEncoding.EncodeToString(imageData)
 
-	var baseResp BaseResponse
+	var resp Response[UploadCoverResponse]
 
-	err := c.getHttpClient(true).Post(uri).
+	err := c.getHttpClient(true).SetContext(ctx).Post(uri).
 		AddParams("t", strconv.FormatInt(time.Now().UnixMilli(), 10)).
 		AddFormData("cover", "data:image/jpeg;base64,"+base64Str).
-		AddFormData("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddFormData("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &UploadCoverResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
-// SubmitVideo 视频投稿 https://member.bilibili.com/x/vu/web/add/v3
-func (c *Client) SubmitVideo(req *SubmitRequest) (*SubmitResponse, error) {
+// submitVideo 视频投稿 https://member.bilibili.com/x/vu/web/add/v3
+func (c *Client) submitVideo(ctx context.Context, req *SubmitRequest) (*SubmitResponse, error) {
 	uri := "https://member.bilibili.com/x/vu/web/add/v3"
 
-	req.CSRF = c.csrf
+	req.CSRF = c.getCSRF()
 
 	reqData, err := json.Marshal(req)
 	if err != nil {
 		return nil, err
 	}
 
-	var baseResp BaseResponse
+	var resp Response[SubmitResponse]
 
-	err = c.getHttpClient(true).
+	err = c.getHttpClient(true).SetContext(ctx).
 		SetContentType("application/json;charset=UTF-8").
 		Post(uri).
 		AddParams("t", strconv.FormatInt(time.Now().UnixMilli(), 10)).
-		AddParams("csrf", c.csrf).
+		AddParams("csrf", c.getCSRF()).
 		SendBody(bytes.NewReader(reqData)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
 
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &SubmitResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetCoin 获取硬币数 https://account.bilibili.com/site/getCoin
 func (c *Client) GetCoin() (*GetCoinResponse, error) {
 	uri := "https://account.bilibili.com/site/getCoin"
 
-	var baseResp BaseResponse
-	err := c.getHttpClient(true).Get(uri).EndStruct(&baseResp)
+	var resp Response[GetCoinResponse]
+	err := c.getHttpClient(true).Get(uri).EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &GetCoinResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetUserInfo 用户空间详细信息 https://api.bilibili.com/x/space/wbi/acc/info
 func (c *Client) GetUserInfo(mid interface{}) (*GetUserInfoResponse, error) {
 	uri := "https://api.bilibili.com/x/space/wbi/acc/info"
 
-	var baseResp BaseResponse
-	err := c.getHttpClient(true).Get(uri).
-		SetWbiKey(c.getWbiKeyCached()).
-		AddParams("mid", cast.ToString(mid)).
-		EndStruct(&baseResp)
+	wbiKey, err := c.getWbiKey(context.Background())
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &GetUserInfoResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
+	var resp Response[GetUserInfoResponse]
+	err = c.getHttpClient(true).Get(uri).
+		SetWbiKey(wbiKey).
+		AddParams("mid", cast.ToString(mid)).
+		EndStruct(&resp)
+	if err != nil {
+		return nil, err
+	}
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetUserCard 用户名片信息 https://api.bilibili.com/x/web-interface/card
@@ -319,109 +286,74 @@ func (c *Client) GetUserInfo(mid interface{}) (*GetUserInfoResponse, error) {
 func (c *Client) GetUserCard(mid interface{}, photo bool) (*GetUserCardResponse, error) {
 	uri := "https://api.bilibili.com/x/web-interface/card"
 
-	var baseResp BaseResponse
+	var resp Response[GetUserCardResponse]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("mid", cast.ToString(mid)).
 		AddParams("photo", strconv.FormatBool(photo)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &GetUserCardResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetMyInfo 登陆用户空间详细信息 https://api.bilibili.com/x/space/myinfo
 func (c *Client) GetMyInfo() (*GetMyInfoResponse, error) {
 	uri := "https://api.bilibili.com/x/space/myinfo"
 
-	var baseResp BaseResponse
-	err := c.getHttpClient(true).Get(uri).EndStruct(&baseResp)
+	var resp Response[GetMyInfoResponse]
+	err := c.getHttpClient(true).Get(uri).EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &GetMyInfoResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetRelationStat 获取用户关系状态 https://api.bilibili.com/x/relation/stat
 func (c *Client) GetRelationStat(mid interface{}) (*GetRelationStatResponse, error) {
 	uri := "https://api.bilibili.com/x/relation/stat"
 
-	var baseResp BaseResponse
+	var resp Response[GetRelationStatResponse]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("vmid", cast.ToString(mid)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &GetRelationStatResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetUpStat 获取up主状态数 https://api.bilibili.com/x/space/upstat
 func (c *Client) GetUpStat(mid interface{}) (*GetUpStatResponse, error) {
 	uri := "https://api.bilibili.com/x/space/upstat"
 
-	var baseResp BaseResponse
+	var resp Response[GetUpStatResponse]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("mid", cast.ToString(mid)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &GetUpStatResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetDocUploadCount 相簿投稿数 https://api.vc.bilibili.com/link_draw/v1/doc/upload_count
 func (c *Client) GetDocUploadCount(mid interface{}) (*GetDocUploadCountResponse, error) {
 	uri := "https://api.vc.bilibili.com/link_draw/v1/doc/upload_count"
 
-	var baseResp BaseResponse
+	var resp Response[GetDocUploadCountResponse]
 	err := c.getHttpClient(false).Get(uri).
 		AddParams("uid", cast.ToString(mid)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &GetDocUploadCountResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetUserFollowers 查询用户粉丝列表 https://api.bilibili.com/x/relation/followers
@@ -432,24 +364,17 @@ func (c *Client) GetDocUploadCount(mid interface{}) (*GetDocUploadCountResponse,
 func (c *Client) GetUserFollowers(mid interface{}, ps int, pn int) (*RelationUserResponse, error) {
 	uri := "https://api.bilibili.com/x/relation/followers"
 
-	var baseResp BaseResponse
+	var resp Response[RelationUserResponse]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("vmid", cast.ToString(mid)).
 		AddParams("ps", strconv.Itoa(ps)).
 		AddParams("pn", strconv.Itoa(pn)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &RelationUserResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetUserFollowings 查询用户关注列表 https://api.bilibili.com/x/relation/followings
@@ -461,25 +386,18 @@ func (c *Client) GetUserFollowers(mid interface{}, ps int, pn int) (*RelationUse
 func (c *Client) GetUserFollowings(mid interface{}, orderType string, ps int, pn int) (*RelationUserResponse, error) {
 	uri := "https://api.bilibili.com/x/relation/followings"
 
-	var baseResp BaseResponse
+	var resp Response[RelationUserResponse]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("vmid", cast.ToString(mid)).
 		AddParams("order_type", orderType).
 		AddParams("ps", strconv.Itoa(ps)).
 		AddParams("pn", strconv.Itoa(pn)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &RelationUserResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetUserFollowingsV2 查询用户关注列表 https://app.biliapi.net/x/v2/relation/followings
@@ -490,24 +408,17 @@ func (c *Client) GetUserFollowings(mid interface{}, orderType string, ps int, pn
 func (c *Client) GetUserFollowingsV2(mid interface{}, ps int, pn int) (*RelationUserResponse, error) {
 	uri := "https://app.biliapi.net/x/v2/relation/followings"
 
-	var baseResp BaseResponse
+	var resp Response[RelationUserResponse]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("vmid", cast.ToString(mid)).
 		AddParams("ps", strconv.Itoa(ps)).
 		AddParams("pn", strconv.Itoa(pn)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &RelationUserResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // SearchUserFollowings 搜索用户关注列表 https://api.bilibili.com/x/relation/followings/search
@@ -518,25 +429,18 @@ func (c *Client) GetUserFollowingsV2(mid interface{}, ps int, pn int) (*Relation
 func (c *Client) SearchUserFollowings(mid interface{}, name string, ps int, pn int) (*RelationUserResponse, error) {
 	uri := "https://api.bilibili.com/x/relation/followings/search"
 
-	var baseResp BaseResponse
+	var resp Response[RelationUserResponse]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("vmid", cast.ToString(mid)).
 		AddParams("name", name).
 		AddParams("ps", strconv.Itoa(ps)).
 		AddParams("pn", strconv.Itoa(pn)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &RelationUserResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetSameFollowings 查询共同关注列表 https://api.bilibili.com/x/relation/same/followings
@@ -546,24 +450,17 @@ func (c *Client) SearchUserFollowings(mid interface{}, name string, ps int, pn i
 func (c *Client) GetSameFollowings(mid interface{}, ps int, pn int) (*RelationUserResponse, error) {
 	uri := "https://api.bilibili.com/x/relation/same/followings"
 
-	var baseResp BaseResponse
+	var resp Response[RelationUserResponse]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("vmid", cast.ToString(mid)).
 		AddParams("ps", strconv.Itoa(ps)).
 		AddParams("pn", strconv.Itoa(pn)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &RelationUserResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetWhispers 查询悄悄关注列表 https://api.bilibili.com/x/relation/whispers
@@ -574,21 +471,14 @@ func (c *Client) GetSameFollowings(mid interface{}, ps int, pn int) (*RelationUs
 func (c *Client) GetWhispers() (*RelationUserResponse, error) {
 	uri := "https://api.bilibili.com/x/relation/whispers"
 
-	var baseResp BaseResponse
+	var resp Response[RelationUserResponse]
 	err := c.getHttpClient(true).Get(uri).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &RelationUserResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetFriends 查询互相关注列表 https://api.bilibili.com/x/relation/friends
@@ -599,21 +489,14 @@ func (c *Client) GetWhispers() (*RelationUserResponse, error) {
 func (c *Client) GetFriends() (*RelationUserResponse, error) {
 	uri := "https://api.bilibili.com/x/relation/friends"
 
-	var baseResp BaseResponse
+	var resp Response[RelationUserResponse]
 	err := c.getHttpClient(true).Get(uri).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &RelationUserResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetBlacks 查询黑名单列表 https://api.bilibili.com/x/relation/blacks
@@ -622,23 +505,16 @@ func (c *Client) GetFriends() (*RelationUserResponse, error) {
 func (c *Client) GetBlacks(ps int, pn int) (*RelationUserResponse, error) {
 	uri := "https://api.bilibili.com/x/relation/blacks"
 
-	var baseResp BaseResponse
+	var resp Response[RelationUserResponse]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("ps", strconv.Itoa(ps)).
 		AddParams("pn", strconv.Itoa(pn)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &RelationUserResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // ModifyRelation 操作用户关系 https://api.bilibili.com/x/relation/modify
@@ -662,21 +538,19 @@ func (c *Client) GetBlacks(ps int, pn int) (*RelationUserResponse, error) {
 func (c *Client) ModifyRelation(mid interface{}, act int, reSrc int) error {
 	uri := "https://api.bilibili.com/x/relation/modify"
 
-	var baseResp BaseResponse
+	var resp Response[json.RawMessage]
 
 	err := c.getHttpClient(true).Post(uri).
 		AddFormData("fid", cast.ToString(mid)).
 		AddFormData("act", strconv.Itoa(act)).
 		AddFormData("re_src", strconv.Itoa(reSrc)).
-		AddFormData("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddFormData("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return fmt.Errorf("%s", bts)
-	}
+
+	c.InvalidateCache(relationEndpoints...)
 
 	return nil
 }
@@ -697,25 +571,20 @@ func (c *Client) ModifyRelation(mid interface{}, act int, reSrc int) error {
 func (c *Client) BatchModifyRelation(mids []string, act int, reSrc int) (*BatchModifyRelationResponse, error) {
 	uri := "https://api.bilibili.com/x/relation/batch/modify"
 
-	var baseResp BaseResponse
+	var resp Response[BatchModifyRelationResponse]
 	err := c.getHttpClient(true).Post(uri).
 		AddFormData("fids", strings.Join(mids, ",")).
 		AddFormData("act", strconv.Itoa(act)).
 		AddFormData("re_src", strconv.Itoa(reSrc)).
-		AddFormData("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddFormData("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &BatchModifyRelationResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
+	c.InvalidateCache(relationEndpoints...)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetRelation 查询用户与自己的关系 https://api.bilibili.com/x/relation
@@ -723,45 +592,36 @@ func (c *Client) BatchModifyRelation(mids []string, act int, reSrc int) (*BatchM
 func (c *Client) GetRelation(mid interface{}) (*Relation, error) {
 	uri := "https://api.bilibili.com/x/relation"
 
-	var baseResp BaseResponse
+	var resp Response[Relation]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("fid", cast.ToString(mid)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &Relation{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetAccRelation 查询用户与自己的互相关系 https://api.bilibili.com/x/space/wbi/acc/relation
 func (c *Client) GetAccRelation(mid interface{}) (*AccRelation, error) {
 	uri := "https://api.bilibili.com/x/space/wbi/acc/relation"
 
-	var baseResp BaseResponse
-	err := c.getHttpClient(true).Get(uri).
-		AddParams("mid", cast.ToString(mid)).
-		SetWbiKey(c.getWbiKeyCached()).
-		EndStruct(&baseResp)
+	wbiKey, err := c.getWbiKey(context.Background())
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &AccRelation{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
+	var resp Response[AccRelation]
+	err = c.getHttpClient(true).Get(uri).
+		AddParams("mid", cast.ToString(mid)).
+		SetWbiKey(wbiKey).
+		EndStruct(&resp)
+	if err != nil {
+		return nil, err
+	}
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // BatchGetRelation 批量查询用户与自己的关系 https://api.bilibili.com/x/relation/relations
@@ -769,43 +629,29 @@ func (c *Client) GetAccRelation(mid interface{}) (*AccRelation, error) {
 func (c *Client) BatchGetRelation(mid ...string) (map[string]Relation, error) {
 	uri := "https://api.bilibili.com/x/relation/relations"
 
-	var baseResp BaseResponse
+	var resp Response[map[string]Relation]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("fids", strings.Join(mid, ",")).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := make(map[string]Relation)
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return resp.Data, nil
 }
 
 // GetRelationTags 查询关注分组列表 https://api.bilibili.com/x/relation/tags
 func (c *Client) GetRelationTags() ([]*RelationTag, error) {
 	uri := "https://api.bilibili.com/x/relation/tags"
 
-	var baseResp BaseResponse
+	var resp Response[[]*RelationTag]
 	err := c.getHttpClient(true).Get(uri).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := make([]*RelationTag, 0)
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return resp.Data, nil
 }
 
 // GetRelationTagUsers 查询关注分组内的用户 https://api.bilibili.com/x/relation/tag
@@ -816,25 +662,18 @@ func (c *Client) GetRelationTags() ([]*RelationTag, error) {
 func (c *Client) GetRelationTagUsers(tagId int, orderType string, ps int, pn int) ([]*RelationUser, error) {
 	uri := "https://api.bilibili.com/x/relation/tag"
 
-	var baseResp BaseResponse
+	var resp Response[[]*RelationUser]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("tagid", strconv.Itoa(tagId)).
 		AddParams("order_type", orderType).
 		AddParams("ps", strconv.Itoa(ps)).
 		AddParams("pn", strconv.Itoa(pn)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := make([]*RelationUser, 0)
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return resp.Data, nil
 }
 
 // QueryRelationTagByUser 查询用户所在的分组 https://api.bilibili.com/x/relation/tag/user
@@ -843,22 +682,15 @@ func (c *Client) GetRelationTagUsers(tagId int, orderType string, ps int, pn int
 func (c *Client) QueryRelationTagByUser(mid interface{}) (map[string]string, error) {
 	uri := "https://api.bilibili.com/x/relation/tag/user"
 
-	var baseResp BaseResponse
+	var resp Response[map[string]string]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("fid", cast.ToString(mid)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := make(map[string]string)
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return resp.Data, nil
 }
 
 // GetSpecialRelationTagUsers 查询特别关注的所有用户mid https://api.bilibili.com/x/relation/tag/special
@@ -866,21 +698,14 @@ func (c *Client) QueryRelationTagByUser(mid interface{}) (map[string]string, err
 func (c *Client) GetSpecialRelationTagUsers() ([]string, error) {
 	uri := "https://api.bilibili.com/x/relation/tag/special"
 
-	var baseResp BaseResponse
+	var resp Response[[]string]
 	err := c.getHttpClient(true).Get(uri).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := make([]string, 0)
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return resp.Data, nil
 }
 
 // CreateRelationTag 创建分组 https://api.bilibili.com/x/relation/tag/create
@@ -888,23 +713,16 @@ func (c *Client) GetSpecialRelationTagUsers() ([]string, error) {
 func (c *Client) CreateRelationTag(name string) (*CreateRelationTagResponse, error) {
 	uri := "https://api.bilibili.com/x/relation/tag/create"
 
-	var baseResp BaseResponse
+	var resp Response[CreateRelationTagResponse]
 	err := c.getHttpClient(true).Post(uri).
 		AddFormData("tag", name).
-		AddFormData("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddFormData("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &CreateRelationTagResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // UpdateRelationTag 更新分组 https://api.bilibili.com/x/relation/tag/update
@@ -913,20 +731,16 @@ func (c *Client) CreateRelationTag(name string) (*CreateRelationTagResponse, err
 func (c *Client) UpdateRelationTag(tagId int, name string) error {
 	uri := "https://api.bilibili.com/x/relation/tag/update"
 
-	var baseResp BaseResponse
+	var resp Response[json.RawMessage]
 
 	err := c.getHttpClient(true).Post(uri).
 		AddFormData("tagid", strconv.Itoa(tagId)).
 		AddFormData("name", name).
-		AddFormData("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddFormData("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return fmt.Errorf("%s", bts)
-	}
 
 	return nil
 }
@@ -935,19 +749,15 @@ func (c *Client) UpdateRelationTag(tagId int, name string) error {
 func (c *Client) DeleteRelationTag(tagId int) error {
 	uri := "https://api.bilibili.com/x/relation/tag/del"
 
-	var baseResp BaseResponse
+	var resp Response[json.RawMessage]
 
 	err := c.getHttpClient(true).Post(uri).
 		AddFormData("tagid", strconv.Itoa(tagId)).
-		AddFormData("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddFormData("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return fmt.Errorf("%s", bts)
-	}
 
 	return nil
 }
@@ -965,20 +775,16 @@ func (c *Client) AddUsersToRelationTags(mids []string, tagIds []int) error {
 		tagIdsString = append(tagIdsString, strconv.Itoa(each))
 	}
 
-	var baseResp BaseResponse
+	var resp Response[json.RawMessage]
 
 	err := c.getHttpClient(true).Post(uri).
 		AddFormData("fids", strings.Join(mids, ",")).
 		AddFormData("tagids", strings.Join(tagIdsString, ",")).
-		AddFormData("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddFormData("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return fmt.Errorf("%s", bts)
-	}
 
 	return nil
 }
@@ -994,20 +800,16 @@ func (c *Client) CopyUsersToRelationTags(mids []string, tagIds []int) error {
 		tagIdsString = append(tagIdsString, strconv.Itoa(each))
 	}
 
-	var baseResp BaseResponse
+	var resp Response[json.RawMessage]
 
 	err := c.getHttpClient(true).Post(uri).
 		AddFormData("fids", strings.Join(mids, ",")).
 		AddFormData("tagids", strings.Join(tagIdsString, ",")).
-		AddFormData("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddFormData("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return fmt.Errorf("%s", bts)
-	}
 
 	return nil
 }
@@ -1028,21 +830,17 @@ func (c *Client) MoveUsersToRelationTags(mids []string, beforeTagIds []int, afte
 		afterTagIdsString = append(afterTagIdsString, strconv.Itoa(each))
 	}
 
-	var baseResp BaseResponse
+	var resp Response[json.RawMessage]
 
 	err := c.getHttpClient(true).Post(uri).
 		AddFormData("fids", strings.Join(mids, ",")).
 		AddFormData("beforeTagids", strings.Join(beforeTagIdsString, ",")).
 		AddFormData("afterTagids", strings.Join(afterTagIdsString, ",")).
-		AddFormData("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddFormData("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return fmt.Errorf("%s", bts)
-	}
 
 	return nil
 }
@@ -1051,48 +849,34 @@ func (c *Client) MoveUsersToRelationTags(mids []string, beforeTagIds []int, afte
 func (c *Client) logout() (*LogoutResponse, error) {
 	uri := "https://passport.bilibili.com/login/exit/v2"
 
-	var baseResp BaseResponse
+	var resp Response[LogoutResponse]
 	err := c.getHttpClient(true).Post(uri).
-		AddFormData("biliCSRF", c.csrf).
-		EndStruct(&baseResp)
+		AddFormData("biliCSRF", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &LogoutResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // getCookieInfo 检查是否需要刷新cookie https://passport.bilibili.com/x/passport-login/web/cookie/info
-func (c *Client) getCookieInfo() (*CookieInfo, error) {
+func (c *Client) getCookieInfo(ctx context.Context) (*CookieInfo, error) {
 	uri := "https://passport.bilibili.com/x/passport-login/web/cookie/info"
 
-	var baseResp BaseResponse
-	err := c.getHttpClient(true).Get(uri).
-		AddParams("biliCSRF", c.csrf).
-		EndStruct(&baseResp)
+	var resp Response[CookieInfo]
+	err := c.getHttpClient(true).SetContext(ctx).Get(uri).
+		AddParams("biliCSRF", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &CookieInfo{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // getRefreshCSRF 获取 refresh_csrf
-func (c *Client) getRefreshCSRF() (string, error) {
+func (c *Client) getRefreshCSRF(ctx context.Context) (string, error) {
 	path, err := utils.GetCorrespondPath(time.Now().UnixMilli())
 	if err != nil {
 		return "", err
@@ -1100,7 +884,7 @@ func (c *Client) getRefreshCSRF() (string, error) {
 
 	uri := "https://www.bilibili.com/correspond/1/" + path
 
-	_, body, err := c.getHttpClient(true).Get(uri).End()
+	_, body, err := c.getHttpClient(true).SetContext(ctx).Get(uri).End()
 	if err != nil {
 		return "", err
 	}
@@ -1114,17 +898,17 @@ func (c *Client) getRefreshCSRF() (string, error) {
 }
 
 // refreshCookie 刷新cookie
-func (c *Client) refreshCookie(refreshCsrf string) (*RefreshCookieResponse, []*http.Cookie, error) {
+func (c *Client) refreshCookie(ctx context.Context, refreshCsrf, refreshToken string) (*RefreshCookieResponse, []*http.Cookie, error) {
 	uri := "https://passport.bilibili.com/x/passport-login/web/cookie/refresh"
 
-	var baseResp BaseResponse
+	var resp Response[RefreshCookieResponse]
 	var cookies []*http.Cookie
 
-	err := c.getHttpClient(true).Post(uri).
-		AddFormData("csrf", c.csrf).
+	err := c.getHttpClient(true).SetContext(ctx).Post(uri).
+		AddFormData("csrf", c.getCSRF()).
 		AddFormData("refresh_csrf", refreshCsrf).
-		AddFormData("refresh_token", c.authInfo.RefreshToken).
-		EndStruct(&baseResp, func(response *http.Response) error {
+		AddFormData("refresh_token", refreshToken).
+		EndStruct(&resp, func(response *http.Response) error {
 			cookies = response.Cookies()
 
 			return nil
@@ -1132,35 +916,24 @@ func (c *Client) refreshCookie(refreshCsrf string) (*RefreshCookieResponse, []*h
 	if err != nil {
 		return nil, nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, nil, fmt.Errorf("%s", bts)
-	}
 
-	rsp := &RefreshCookieResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, cookies, err
+	return &resp.Data, cookies, nil
 
 }
 
 // confirmRefresh 确认更新
-func (c *Client) confirmRefresh(refreshToken string) error {
+func (c *Client) confirmRefresh(ctx context.Context, refreshToken string) error {
 	uri := "https://passport.bilibili.com/x/passport-login/web/confirm/refresh"
 
-	var baseResp BaseResponse
+	var resp Response[json.RawMessage]
 
-	err := c.getHttpClient(true).Post(uri).
-		AddFormData("csrf", c.csrf).
+	err := c.getHttpClient(true).SetContext(ctx).Post(uri).
+		AddFormData("csrf", c.getCSRF()).
 		AddFormData("refresh_token", refreshToken).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return fmt.Errorf("%s", bts)
-	}
 
 	return nil
 }
@@ -1169,21 +942,14 @@ func (c *Client) confirmRefresh(refreshToken string) error {
 func (c *Client) GetExpReword() (*ExpReward, error) {
 	uri := "https://api.bilibili.com/x/member/web/exp/reward"
 
-	var baseResp BaseResponse
+	var resp Response[ExpReward]
 	err := c.getHttpClient(true).Get(uri).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &ExpReward{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // CoinVideo 视频投币
@@ -1200,18 +966,17 @@ func (c *Client) CoinVideo(id string, coins int) error {
 		httpClient.AddParams("aid", id)
 	}
 
-	var baseResp BaseResponse
+	var resp Response[json.RawMessage]
 	err := httpClient.
 		AddParams("multiply", strconv.Itoa(coins)).
-		AddParams("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddParams("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return fmt.Errorf("%s", bts)
-	}
+
+	// 导航栏信息中包含硬币数
+	c.InvalidateCache("api.bilibili.com/x/web-interface/nav")
 
 	return nil
 }
@@ -1230,21 +995,14 @@ func (c *Client) HasCoinVideo(id string) (int, error) {
 		httpClient.AddParams("aid", id)
 	}
 
-	var baseResp BaseResponse
+	var resp Response[map[string]int]
 	err := httpClient.
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return 0, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return 0, fmt.Errorf("%s", bts)
-	}
 
-	var data map[string]int
-	err = json.Unmarshal(baseResp.RawData(), &data)
-
-	return data["multiply"], err
+	return resp.Data["multiply"], nil
 }
 
 // ShareVideo 分享视频
@@ -1261,22 +1019,15 @@ func (c *Client) ShareVideo(id string) (int, error) {
 		httpClient.AddParams("aid", id)
 	}
 
-	var baseResp BaseResponse
+	var resp Response[int]
 	err := httpClient.
-		AddParams("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddParams("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return 0, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return 0, fmt.Errorf("%s", bts)
-	}
-
-	var data int
-	err = json.Unmarshal(baseResp.RawData(), &data)
 
-	return data, err
+	return resp.Data, nil
 }
 
 // likeVideo 点赞视频
@@ -1293,18 +1044,14 @@ func (c *Client) likeVideo(id string, like int) error {
 		httpClient.AddParams("aid", id)
 	}
 
-	var baseResp BaseResponse
+	var resp Response[json.RawMessage]
 	err := httpClient.
 		AddParams("like", strconv.Itoa(like)).
-		AddParams("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddParams("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return fmt.Errorf("%s", bts)
-	}
 
 	return nil
 }
@@ -1323,21 +1070,14 @@ func (c *Client) HasLikeVideo(id string) (int, error) {
 		httpClient.AddParams("aid", id)
 	}
 
-	var baseResp BaseResponse
+	var resp Response[int]
 	err := httpClient.
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return 0, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return 0, fmt.Errorf("%s", bts)
-	}
-
-	var data int
-	err = json.Unmarshal(baseResp.RawData(), &data)
 
-	return data, err
+	return resp.Data, nil
 }
 
 // TripleVideo 一键三连
@@ -1353,22 +1093,15 @@ func (c *Client) TripleVideo(id string) (*TripleVideoResponse, error) {
 		httpClient.AddParams("aid", id)
 	}
 
-	var baseResp BaseResponse
+	var resp Response[TripleVideoResponse]
 	err := httpClient.
-		AddParams("csrf", c.csrf).
-		EndStruct(&baseResp)
+		AddParams("csrf", c.getCSRF()).
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &TripleVideoResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
 
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetPopularVideoList 获取热门视频列表
@@ -1378,24 +1111,16 @@ func (c *Client) TripleVideo(id string) (*TripleVideoResponse, error) {
 func (c *Client) GetPopularVideoList(pn int, ps int, common bool) (*GetPopularVideoListResponse, error) {
 	uri := "https://api.bilibili.com/x/web-interface/popular"
 
-	var baseResp BaseResponse
+	var resp Response[GetPopularVideoListResponse]
 	err := c.getHttpClient(!common).Get(uri).
 		AddParams("pn", strconv.Itoa(pn)).
 		AddParams("ps", strconv.Itoa(ps)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
 
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &GetPopularVideoListResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetVideoRank 获取视频排行榜
@@ -1403,29 +1128,16 @@ func (c *Client) GetPopularVideoList(pn int, ps int, common bool) (*GetPopularVi
 func (c *Client) GetVideoRank(tid int) ([]*Video, error) {
 	uri := "https://api.bilibili.com/x/web-interface/ranking/v2"
 
-	var baseResp BaseResponse
+	var resp Response[videoList]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("rid", strconv.Itoa(tid)).
 		AddParams("type", "all").
-		EndStruct(&baseResp)
-	if err != nil {
-		return nil, err
-	}
-
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &struct {
-		List []*Video `json:"list"`
-	}{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
 
-	return rsp.List, nil
+	return resp.Data.List, nil
 }
 
 // GetLatestVideo 最新视频列表
@@ -1435,47 +1147,116 @@ func (c *Client) GetVideoRank(tid int) ([]*Video, error) {
 func (c *Client) GetLatestVideo(pn int, ps int, tid int) (*GetLatestVideoResponse, error) {
 	uri := "https://api.bilibili.com/x/web-interface/dynamic/region"
 
-	var baseResp BaseResponse
+	var resp Response[GetLatestVideoResponse]
 	err := c.getHttpClient(true).Get(uri).
 		AddParams("pn", strconv.Itoa(pn)).
 		AddParams("ps", strconv.Itoa(ps)).
 		AddParams("rid", strconv.Itoa(tid)).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
 
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
-	}
-
-	rsp := &GetLatestVideoResponse{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
-
-	return rsp, err
+	return &resp.Data, nil
 }
 
 // GetPreciousVideo 入站必刷视频
 func (c *Client) GetPreciousVideo() ([]*Video, error) {
 	uri := "https://api.bilibili.com/x/web-interface/popular/precious"
 
-	var baseResp BaseResponse
+	var resp Response[videoList]
 	err := c.getHttpClient(true).Get(uri).
-		EndStruct(&baseResp)
+		EndStruct(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.List, nil
+}
+
+// getArchiveView 获取创作中心的稿件详情 https://member.bilibili.com/x/vupre/web/archive/view
+// id 视频ID av号或者bv号
+func (c *Client) getArchiveView(ctx context.Context, id string) (*ArchiveViewResponse, error) {
+	uri := "https://member.bilibili.com/x/vupre/web/archive/view"
+
+	httpClient := c.getHttpClient(true).SetContext(ctx).Get(uri)
+
+	if strings.HasPrefix(strings.ToLower(id), "bv") {
+		httpClient.AddParams("bvid", "BV"+id[2:])
+	} else {
+		httpClient.AddParams("aid", strings.TrimPrefix(strings.ToLower(id), "av"))
+	}
+
+	var resp Response[ArchiveViewResponse]
+	err := httpClient.EndStruct(&resp)
 	if err != nil {
 		return nil, err
 	}
 
-	if baseResp.Code != CodeSuccess {
-		bts, _ := json.Marshal(baseResp)
-		return nil, fmt.Errorf("%s", bts)
+	return &resp.Data, nil
+}
+
+// editArchive 编辑稿件 https://member.bilibili.com/x/vu/web/edit
+func (c *Client) editArchive(ctx context.Context, req *ArchiveEdit) (*SubmitResponse, error) {
+	uri := "https://member.bilibili.com/x/vu/web/edit"
+
+	// 在副本上设置 csrf，不修改调用方的 req
+	edit := *req
+	edit.CSRF = c.getCSRF()
+
+	reqData, err := json.Marshal(&edit)
+	if err != nil {
+		return nil, err
 	}
 
-	rsp := &struct {
-		List []*Video `json:"list"`
-	}{}
-	err = json.Unmarshal(baseResp.RawData(), &rsp)
+	var resp Response[SubmitResponse]
 
-	return rsp.List, err
+	err = c.getHttpClient(true).SetContext(ctx).
+		SetContentType("application/json;charset=UTF-8").
+		Post(uri).
+		AddParams("t", strconv.FormatInt(time.Now().UnixMilli(), 10)).
+		AddParams("csrf", c.getCSRF()).
+		SendBody(bytes.NewReader(reqData)).
+		EndStruct(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// getCreatorArchives 创作中心稿件列表 https://member.bilibili.com/x/web/archives
+// status 多个状态用逗号分隔，为空则获取全部
+func (c *Client) getCreatorArchives(ctx context.Context, status string, pn int, ps int) (*GetCreatorArchivesResponse, error) {
+	uri := "https://member.bilibili.com/x/web/archives"
+
+	var resp Response[GetCreatorArchivesResponse]
+	err := c.getHttpClient(true).SetContext(ctx).Get(uri).
+		AddParams("status", status).
+		AddParams("pn", strconv.Itoa(pn)).
+		AddParams("ps", strconv.Itoa(ps)).
+		AddParams("coop", "1").
+		AddParams("interactive", "1").
+		EndStruct(&resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp.Data, nil
+}
+
+// deleteArchive 删除稿件 https://member.bilibili.com/x/web/archive/delete
+func (c *Client) deleteArchive(ctx context.Context, aid int64) error {
+	uri := "https://member.bilibili.com/x/web/archive/delete"
+
+	var resp Response[json.RawMessage]
+	err := c.getHttpClient(true).SetContext(ctx).Post(uri).
+		AddFormData("aid", strconv.FormatInt(aid, 10)).
+		AddFormData("csrf", c.getCSRF()).
+		EndStruct(&resp)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
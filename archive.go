@@ -0,0 +1,252 @@
+package bilibili_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// defaultPublishConcurrency 默认同时上传的分P数
+const defaultPublishConcurrency = 2
+
+// ArchivePart 稿件的一个分P，Path 和 Reader 二选一
+type ArchivePart struct {
+	// Title 分P标题，为空则使用文件名
+	Title string
+	Desc  string
+
+	// Filename 上传的文件名，为空则使用 Path 的文件名，使用 Reader 时必须设置
+	Filename string
+
+	// Path 本地视频文件
+	Path string
+
+	// Reader 视频内容，实现 io.ReaderAt 并且 Size 大于0时不会整个读入内存
+	Reader io.Reader
+
+	// Size Reader 的大小，未知时为0，此时会先写入临时文件
+	Size int64
+
+	// Video 已经上传的视频，不为nil时跳过上传，用于失败后只重新上传失败的分P
+	Video *SubmitVideo
+
+	// Options 该分P的上传选项，在 PublishArchiveRequest.UploadOptions 之后生效
+	Options []UploadOption
+}
+
+// PublishArchiveRequest 多P投稿，SubmitRequest.Videos 会按 Parts 的顺序生成
+type PublishArchiveRequest struct {
+	SubmitRequest
+
+	// CoverImage 封面图片，SubmitRequest.Cover 为空时上传
+	CoverImage []byte
+
+	Parts []ArchivePart
+
+	// Concurrency 同时上传的分P数，默认为2，每个分P内部的分片并发见 WithUploadThreads
+	Concurrency int
+
+	// UploadOptions 所有分P共用的上传选项
+	UploadOptions []UploadOption
+}
+
+// PartResult 分P的上传结果
+type PartResult struct {
+	Index int
+	Title string
+	Video *SubmitVideo
+	Err   error
+}
+
+// PublishError 多P投稿失败，Parts 按顺序记录每个分P的上传结果，上传成功的分P可以通过 ArchivePart.Video 复用
+type PublishError struct {
+	Parts []PartResult
+
+	// Cover 封面上传失败的原因
+	Cover error
+
+	// Submit 投稿失败的原因，分P或封面上传失败时不会投稿
+	Submit error
+}
+
+func (e *PublishError) Error() string {
+	var msgs []string
+	if failed := e.Failed(); len(failed) > 0 {
+		msgs = append(msgs, fmt.Sprintf("%d/%d parts failed", len(failed), len(e.Parts)))
+		for _, part := range failed {
+			msgs = append(msgs, fmt.Sprintf("part %d %q: %v", part.Index+1, part.Title, part.Err))
+		}
+	}
+	if e.Cover != nil {
+		msgs = append(msgs, fmt.Sprintf("upload cover: %v", e.Cover))
+	}
+	if e.Submit != nil {
+		msgs = append(msgs, fmt.Sprintf("submit: %v", e.Submit))
+	}
+
+	return "publish archive: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap 返回所有失败原因，用于 errors.Is 和 errors.As
+func (e *PublishError) Unwrap() []error {
+	var errs []error
+	for _, part := range e.Failed() {
+		errs = append(errs, part.Err)
+	}
+	if e.Cover != nil {
+		errs = append(errs, e.Cover)
+	}
+	if e.Submit != nil {
+		errs = append(errs, e.Submit)
+	}
+
+	return errs
+}
+
+// Failed 上传失败的分P
+func (e *PublishError) Failed() []PartResult {
+	var failed []PartResult
+	for _, part := range e.Parts {
+		if part.Err != nil {
+			failed = append(failed, part)
+		}
+	}
+
+	return failed
+}
+
+// PublishArchive 并发上传所有分P和封面后按顺序投稿，某个分P失败时其他分P会继续上传，
+// 失败时返回 *PublishError，其中包含每个分P的结果
+func (c *Client) PublishArchive(ctx context.Context, req *PublishArchiveRequest) (resp *SubmitResponse, err error) {
+	if len(req.Parts) == 0 {
+		return nil, errors.New("no archive parts")
+	}
+
+	ctx, span := c.startSpan(ctx, "PublishArchive", Attr(AttrVideos, len(req.Parts)))
+	ctx = withRequestID(ctx)
+	defer func() { span.End(err) }()
+
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultPublishConcurrency
+	}
+
+	publishErr := &PublishError{Parts: make([]PartResult, len(req.Parts))}
+	var wg sync.WaitGroup
+
+	submit := req.SubmitRequest
+	if submit.Cover == "" && len(req.CoverImage) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cover, err := c.uploadCover(ctx, req.CoverImage)
+			if err != nil {
+				publishErr.Cover = err
+				return
+			}
+			submit.Cover = cover.Url
+		}()
+	}
+
+	sem := make(chan struct{}, concurrency)
+	for i, part := range req.Parts {
+		wg.Add(1)
+		go func(i int, part ArchivePart) {
+			defer wg.Done()
+
+			result := &publishErr.Parts[i]
+			result.Index, result.Title = i, part.Title
+			if part.Video != nil {
+				result.Video = part.Video
+			} else {
+				select {
+				case sem <- struct{}{}:
+					result.Video, result.Err = c.uploadArchivePart(ctx, part, req.UploadOptions)
+					<-sem
+				case <-ctx.Done():
+					result.Err = ctx.Err()
+				}
+			}
+			if result.Err != nil {
+				c.log(ctx, SubsystemUpload, LevelWarn, "upload archive part failed", KV("part", i+1), KV(FieldError, result.Err))
+				return
+			}
+
+			if result.Title == "" {
+				result.Title = result.Video.Title
+			}
+			c.log(ctx, SubsystemUpload, LevelInfo, "archive part uploaded", KV("part", i+1), KV("cid", result.Video.CID))
+		}(i, part)
+	}
+	wg.Wait()
+
+	if len(publishErr.Failed()) > 0 || publishErr.Cover != nil {
+		return nil, publishErr
+	}
+
+	submit.Videos = make([]*SubmitVideo, len(req.Parts))
+	for i, part := range req.Parts {
+		video := *publishErr.Parts[i].Video
+		if part.Title != "" {
+			video.Title = part.Title
+		}
+		if part.Desc != "" {
+			video.Desc = part.Desc
+		}
+		submit.Videos[i] = &video
+	}
+
+	resp, err = c.SubmitVideoContext(ctx, &submit)
+	if err != nil {
+		publishErr.Submit = err
+		return nil, publishErr
+	}
+
+	return resp, nil
+}
+
+// uploadArchivePart 上传一个分P
+func (c *Client) uploadArchivePart(ctx context.Context, part ArchivePart, opts []UploadOption) (*SubmitVideo, error) {
+	opts = append(append([]UploadOption{}, opts...), part.Options...)
+
+	filename := part.Filename
+	if filename == "" {
+		filename = filepath.Base(part.Path)
+	}
+
+	if part.Path != "" {
+		file, err := os.Open(part.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+
+		return c.UploadVideoReaderAt(ctx, filename, file, info.Size(), opts...)
+	}
+
+	if part.Reader == nil {
+		return nil, errors.New("archive part has neither Path nor Reader")
+	}
+	if part.Filename == "" {
+		return nil, errors.New("archive part with Reader requires Filename")
+	}
+	if readerAt, ok := part.Reader.(io.ReaderAt); ok && part.Size > 0 {
+		return c.UploadVideoReaderAt(ctx, filename, readerAt, part.Size, opts...)
+	}
+	size := part.Size
+	if size <= 0 {
+		size = -1
+	}
+
+	return c.UploadVideoStream(ctx, filename, part.Reader, size, opts...)
+}
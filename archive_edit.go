@@ -0,0 +1,120 @@
+package bilibili_go
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ArchiveEdit 可编辑的稿件，通过 GetArchiveEdit 获取，修改后调用 EditArchive 提交。
+// Videos 为稿件的所有分P，已有分P通过 CID 对应，新上传的分P直接使用上传返回的 SubmitVideo
+type ArchiveEdit struct {
+	Aid  int64  `json:"aid"`
+	Bvid string `json:"-"`
+	SubmitRequest
+}
+
+// GetArchiveEdit 获取稿件当前的信息用于编辑
+// id 视频ID av号或者bv号
+func (c *Client) GetArchiveEdit(ctx context.Context, id string) (*ArchiveEdit, error) {
+	view, err := c.getArchiveView(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	edit := &ArchiveEdit{
+		Aid:           view.Archive.Aid,
+		Bvid:          view.Archive.Bvid,
+		SubmitRequest: view.Archive.SubmitRequest,
+	}
+	edit.Videos = make([]*SubmitVideo, 0, len(view.Videos))
+	for _, video := range view.Videos {
+		edit.Videos = append(edit.Videos, &SubmitVideo{
+			Filename: video.Filename,
+			Title:    video.Title,
+			Desc:     video.Desc,
+			CID:      video.CID,
+		})
+	}
+
+	return edit, nil
+}
+
+// EditArchive 提交稿件修改，修改后稿件需要重新审核
+func (c *Client) EditArchive(ctx context.Context, edit *ArchiveEdit) (resp *SubmitResponse, err error) {
+	ctx, span := c.startSpan(ctx, "EditArchive", Attr(AttrAid, edit.Aid), Attr(AttrVideos, len(edit.Videos)))
+	defer func() { span.End(err) }()
+
+	if len(edit.Videos) == 0 {
+		return nil, fmt.Errorf("archive %d has no videos", edit.Aid)
+	}
+
+	return c.editArchive(ctx, edit)
+}
+
+// Tags 稿件的标签
+func (e *ArchiveEdit) Tags() []string {
+	if e.Tag == "" {
+		return nil
+	}
+
+	return strings.Split(e.Tag, ",")
+}
+
+// SetTags 设置稿件的标签
+func (e *ArchiveEdit) SetTags(tags ...string) {
+	e.Tag = strings.Join(tags, ",")
+}
+
+// AddPart 在最后添加分P
+func (e *ArchiveEdit) AddPart(video *SubmitVideo) {
+	e.Videos = append(e.Videos, video)
+}
+
+// InsertPart 在 index 处插入分P，index 从0开始
+func (e *ArchiveEdit) InsertPart(index int, video *SubmitVideo) error {
+	if index < 0 || index > len(e.Videos) {
+		return fmt.Errorf("part index %d out of range [0, %d]", index, len(e.Videos))
+	}
+
+	e.Videos = append(e.Videos, nil)
+	copy(e.Videos[index+1:], e.Videos[index:])
+	e.Videos[index] = video
+
+	return nil
+}
+
+// RemovePart 删除 index 处的分P，index 从0开始
+func (e *ArchiveEdit) RemovePart(index int) error {
+	if index < 0 || index >= len(e.Videos) {
+		return fmt.Errorf("part index %d out of range [0, %d)", index, len(e.Videos))
+	}
+
+	e.Videos = append(e.Videos[:index], e.Videos[index+1:]...)
+
+	return nil
+}
+
+// MovePart 将 from 处的分P移动到 to 处，index 从0开始
+func (e *ArchiveEdit) MovePart(from, to int) error {
+	if from < 0 || from >= len(e.Videos) || to < 0 || to >= len(e.Videos) {
+		return fmt.Errorf("part index %d or %d out of range [0, %d)", from, to, len(e.Videos))
+	}
+
+	video := e.Videos[from]
+	e.Videos = append(e.Videos[:from], e.Videos[from+1:]...)
+	e.Videos = append(e.Videos[:to], append([]*SubmitVideo{video}, e.Videos[to:]...)...)
+
+	return nil
+}
+
+// PartByCID 按 CID 查找分P的位置，不存在时返回-1
+func (e *ArchiveEdit) PartByCID(cid int) int {
+	for i, video := range e.Videos {
+		if video.CID == cid {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,75 @@
+package bilibili_go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestEditArchive(t *testing.T) {
+	var edited map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/x/vupre/web/archive/view", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("bvid") != "BV1xx411c7mD" {
+			_, _ = w.Write([]byte(`{"code":-404,"message":"啥都木有"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0,"data":{"archive":{"aid":1,"bvid":"BV1xx411c7mD","title":"old","desc":"desc",` +
+			`"tag":"a,b","tid":171,"copyright":2,"source":"https://example.com","cover":"https://i0.hdslb.com/old.jpg"},` +
+			`"videos":[{"aid":1,"cid":11,"index":1,"title":"p1","filename":"f1"},{"aid":1,"cid":12,"index":2,"title":"p2","filename":"f2"}]}}`))
+	})
+	mux.HandleFunc("/x/vu/web/edit", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&edited)
+		_, _ = w.Write([]byte(`{"code":0,"data":{"aid":1,"bvid":"BV1xx411c7mD"}}`))
+	})
+	client := newTestClient(t, mux)
+
+	if _, err := client.GetArchiveEdit(context.Background(), "BV1yy411c7mD"); err == nil {
+		t.Error("GetArchiveEdit() should fail for unknown archive")
+	}
+
+	// bv 前缀不区分大小写
+	edit, err := client.GetArchiveEdit(context.Background(), "bv1xx411c7mD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edit.Aid != 1 || edit.Title != "old" || edit.TID != 171 || edit.Source != "https://example.com" || !reflect.DeepEqual(edit.Tags(), []string{"a", "b"}) {
+		t.Fatalf("GetArchiveEdit() = %+v", edit)
+	}
+
+	edit.Title = "new"
+	edit.SetTags("c")
+	edit.AddPart(&SubmitVideo{Filename: "f3", Title: "p3", CID: 13})
+	if err := edit.MovePart(2, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := edit.RemovePart(edit.PartByCID(11)); err != nil {
+		t.Fatal(err)
+	}
+	if err := edit.InsertPart(1, &SubmitVideo{Filename: "f4", Title: "p4", CID: 14}); err != nil {
+		t.Fatal(err)
+	}
+	if err := edit.RemovePart(5); err == nil {
+		t.Error("RemovePart() out of range should fail")
+	}
+
+	client.csrf = "token"
+	if _, err := client.EditArchive(context.Background(), edit); err != nil {
+		t.Fatal(err)
+	}
+	if edited["aid"] != float64(1) || edited["title"] != "new" || edited["tag"] != "c" || edited["cover"] != "https://i0.hdslb.com/old.jpg" || edited["csrf"] != "token" {
+		t.Errorf("edit request = %v", edited)
+	}
+	if edit.CSRF != "" {
+		t.Errorf("EditArchive() should not modify the caller's edit, csrf = %q", edit.CSRF)
+	}
+	var cids []float64
+	for _, video := range edited["videos"].([]any) {
+		cids = append(cids, video.(map[string]any)["cid"].(float64))
+	}
+	if !reflect.DeepEqual(cids, []float64{13, 14, 12}) {
+		t.Errorf("edited cids = %v, want [13 14 12]", cids)
+	}
+}
@@ -0,0 +1,108 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// archiveServer 在 uploadServer 的基础上模拟封面上传和投稿接口，badFiles 中的文件预上传失败
+type archiveServer struct {
+	*uploadServer
+	mu        sync.Mutex
+	badFiles  map[string]bool
+	preupload int
+	submitted *SubmitRequest
+}
+
+func (s *archiveServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/preupload":
+		s.mu.Lock()
+		s.preupload++
+		s.mu.Unlock()
+		if s.badFiles[r.URL.Query().Get("name")] {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+	case "/x/vu/web/cover/up":
+		_, _ = w.Write([]byte(`{"code":0,"data":{"url":"https://i0.hdslb.com/cover.jpg"}}`))
+		return
+	case "/x/vu/web/add/v3":
+		var req SubmitRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.mu.Lock()
+		s.submitted = &req
+		s.mu.Unlock()
+		_, _ = w.Write([]byte(`{"code":0,"data":{"aid":1,"bvid":"BV1xx411c7mD"}}`))
+		return
+	}
+
+	s.uploadServer.ServeHTTP(w, r)
+}
+
+func TestPublishArchive(t *testing.T) {
+	server := &archiveServer{uploadServer: newUploadServer(), badFiles: map[string]bool{"p3.mp4": true}}
+	client := newTestClient(t, server)
+
+	req := &PublishArchiveRequest{
+		SubmitRequest: SubmitRequest{Title: "series", TID: 171},
+		CoverImage:    []byte("cover"),
+		Concurrency:   2,
+	}
+	for _, name := range []string{"p1.mp4", "p2.mp4", "p3.mp4", "p4.mp4"} {
+		req.Parts = append(req.Parts, ArchivePart{Title: "part " + name, Filename: name, Reader: bytes.NewReader(testVideo(testChunkSize)), Size: testChunkSize})
+	}
+
+	_, err := client.PublishArchive(context.Background(), req)
+	var publishErr *PublishError
+	if !errors.As(err, &publishErr) {
+		t.Fatalf("PublishArchive() error = %v, want *PublishError", err)
+	}
+	if failed := publishErr.Failed(); len(failed) != 1 || failed[0].Index != 2 || server.submitted != nil {
+		t.Fatalf("failed parts = %+v, submitted = %v", failed, server.submitted)
+	}
+
+	// 复用已经上传的分P，只重新上传失败的分P
+	for i, part := range publishErr.Parts {
+		req.Parts[i].Video = part.Video
+	}
+	server.badFiles = nil
+	server.preupload = 0
+	resp, err := client.PublishArchive(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Bvid != "BV1xx411c7mD" || server.preupload != 1 {
+		t.Errorf("resp = %+v, preupload = %d", resp, server.preupload)
+	}
+
+	submitted := server.submitted
+	if submitted.Cover != "https://i0.hdslb.com/cover.jpg" || submitted.Title != "series" || len(submitted.Videos) != 4 {
+		t.Fatalf("submitted = %+v", submitted)
+	}
+	for i, video := range submitted.Videos {
+		if video.Title != req.Parts[i].Title || video.Filename == "" {
+			t.Errorf("video %d = %+v", i, video)
+		}
+	}
+}
+
+func TestPublishArchiveReaderWithoutFilename(t *testing.T) {
+	server := &archiveServer{uploadServer: newUploadServer()}
+	client := newTestClient(t, server)
+
+	req := &PublishArchiveRequest{
+		SubmitRequest: SubmitRequest{Title: "series", TID: 171},
+		Parts:         []ArchivePart{{Reader: bytes.NewReader(testVideo(testChunkSize)), Size: testChunkSize}},
+	}
+	_, err := client.PublishArchive(context.Background(), req)
+	var publishErr *PublishError
+	if !errors.As(err, &publishErr) || len(publishErr.Failed()) != 1 || server.preupload != 0 {
+		t.Fatalf("PublishArchive() error = %v, preupload = %d", err, server.preupload)
+	}
+}
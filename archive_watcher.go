@@ -0,0 +1,470 @@
+package bilibili_go
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ArchiveEventType 稿件状态事件类型
+type ArchiveEventType int
+
+const (
+	ArchiveTranscoding ArchiveEventType = iota + 1 // 转码中
+	ArchiveReviewing                               // 审核中
+	ArchivePublished                               // 已发布
+	ArchiveRejected                                // 被打回或转码失败，Reason 为退回原因
+	ArchiveLocked                                  // 被锁定
+	ArchiveDeleted                                 // 已删除
+	ArchiveWatchFailed                             // 获取状态失败并且无法重试，比如稿件不存在，Err 为失败原因
+)
+
+var archiveEventTypeNames = map[ArchiveEventType]string{
+	ArchiveTranscoding: "transcoding",
+	ArchiveReviewing:   "reviewing",
+	ArchivePublished:   "published",
+	ArchiveRejected:    "rejected",
+	ArchiveLocked:      "locked",
+	ArchiveDeleted:     "deleted",
+	ArchiveWatchFailed: "failed",
+}
+
+func (t ArchiveEventType) String() string {
+	if name, ok := archiveEventTypeNames[t]; ok {
+		return name
+	}
+
+	return "unknown"
+}
+
+// Final 是否为最终状态，之后不会再监听该稿件
+func (t ArchiveEventType) Final() bool {
+	return t != ArchiveTranscoding && t != ArchiveReviewing
+}
+
+// ArchiveEvent 稿件状态变化
+type ArchiveEvent struct {
+	// ID 监听时传入的av号或者bv号
+	ID   string
+	Type ArchiveEventType
+
+	// Reason 退回或锁定的原因，稿件没有原因时使用第一个有原因的分P
+	Reason string
+
+	// Review 最新的审核状态，ArchiveWatchFailed 时为nil
+	Review *ArchiveReview
+
+	Err error
+}
+
+// WatchPolicy 轮询策略
+type WatchPolicy struct {
+	// Interval 第一次轮询间隔，状态没有变化时每次翻倍，默认30s
+	Interval time.Duration
+
+	// MaxInterval 最长轮询间隔，默认10m
+	MaxInterval time.Duration
+}
+
+func (p WatchPolicy) withDefaults() WatchPolicy {
+	if p.Interval <= 0 {
+		p.Interval = 30 * time.Second
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = 10 * time.Minute
+	}
+	if p.MaxInterval < p.Interval {
+		p.MaxInterval = p.Interval
+	}
+
+	return p
+}
+
+// archiveEvent 根据审核状态生成事件
+func archiveEvent(id string, review *ArchiveReview) ArchiveEvent {
+	event := ArchiveEvent{ID: id, Review: review}
+
+	switch state := review.State; {
+	case state.IsOpen():
+		event.Type = ArchivePublished
+	case state == ArchiveStateDeleted:
+		event.Type = ArchiveDeleted
+	case state.IsLocked():
+		event.Type = ArchiveLocked
+	case state.IsRejected():
+		event.Type = ArchiveRejected
+	case state.IsTranscoding():
+		event.Type = ArchiveTranscoding
+	default:
+		event.Type = ArchiveReviewing
+	}
+
+	if event.Type == ArchiveRejected || event.Type == ArchiveLocked {
+		event.Reason = review.RejectReason
+		for _, video := range review.Videos {
+			if event.Reason != "" {
+				break
+			}
+			event.Reason = video.RejectReason
+		}
+	}
+
+	return event
+}
+
+// isFinalWatchError 稿件不存在或者没有权限时无法继续监听，风控、频率限制、未登录等错误会在下次轮询时重试
+func isFinalWatchError(err error) bool {
+	return IsCode(err, CodeNotFound) || IsCode(err, CodeAccessDenied)
+}
+
+// nextInterval 状态没有变化时轮询间隔翻倍，最长为 MaxInterval
+func (p WatchPolicy) nextInterval(interval time.Duration) time.Duration {
+	if interval *= 2; interval > p.MaxInterval {
+		interval = p.MaxInterval
+	}
+
+	return interval
+}
+
+// watchArchive 轮询稿件状态直到最终状态或者 ctx 结束，状态变化时调用 emit，返回最终事件。
+// 稿件不存在或者没有权限时以 ArchiveWatchFailed 结束，其他错误按轮询间隔重试
+func (c *Client) watchArchive(ctx context.Context, id string, policy WatchPolicy, emit func(ArchiveEvent) bool) (*ArchiveEvent, error) {
+	policy = policy.withDefaults()
+	interval := policy.Interval
+
+	var last *ArchiveEvent
+	for {
+		review, err := c.GetArchiveReview(ctx, id)
+		switch {
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case isFinalWatchError(err):
+			event := ArchiveEvent{ID: id, Type: ArchiveWatchFailed, Err: err}
+			emit(event)
+			return &event, nil
+		case err != nil:
+			c.log(ctx, SubsystemUpload, LevelWarn, "GetArchiveReview failed", KV("id", id), KV(FieldError, err))
+		default:
+			event := archiveEvent(id, review)
+			if last == nil || last.Type != event.Type || last.Reason != event.Reason {
+				if !emit(event) {
+					return nil, ctx.Err()
+				}
+				interval = policy.Interval
+			}
+			last = &event
+			if event.Type.Final() {
+				return last, nil
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		interval = policy.nextInterval(interval)
+	}
+}
+
+// WaitArchive 轮询稿件状态直到发布、被打回、锁定、删除或者无法获取状态，返回最终事件，ctx 结束时返回 ctx.Err()
+// id 视频ID av号或者bv号
+func (c *Client) WaitArchive(ctx context.Context, id string, policy WatchPolicy) (*ArchiveEvent, error) {
+	return c.watchArchive(ctx, id, policy, func(ArchiveEvent) bool { return true })
+}
+
+// ErrWatcherClosed 稿件监听已经 Close，还有稿件没有到达最终状态
+var ErrWatcherClosed = errors.New("archive watcher closed")
+
+// ArchiveWatcher 同时监听多个稿件的状态，每个稿件状态变化时通过 Events 发送事件，
+// 到达最终状态后停止监听该稿件。Events 需要及时读取，否则会阻塞轮询。
+// 每次轮询只获取一次创作中心"进行中"的稿件列表，不在列表中的稿件（已经有审核结果）才单独获取状态
+type ArchiveWatcher struct {
+	client   *Client
+	policy   WatchPolicy
+	ctx      context.Context
+	cancel   context.CancelFunc
+	events   chan ArchiveEvent
+	wake     chan struct{} // 有新的稿件时立即轮询
+	mu       sync.Mutex
+	archives map[string]*watchedArchive
+	order    []string // 按 Watch 的顺序轮询
+	running  bool
+	stopped  bool // 轮询已经结束
+	loopDone chan struct{}
+	closed   bool
+}
+
+type watchedArchive struct {
+	done  chan struct{}
+	last  *ArchiveEvent
+	final *ArchiveEvent
+}
+
+// NewArchiveWatcher 创建稿件监听，ctx 结束或者调用 Close 后停止所有监听
+func (c *Client) NewArchiveWatcher(ctx context.Context, policy WatchPolicy) *ArchiveWatcher {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &ArchiveWatcher{
+		client:   c,
+		policy:   policy.withDefaults(),
+		ctx:      ctx,
+		cancel:   cancel,
+		events:   make(chan ArchiveEvent, 16),
+		wake:     make(chan struct{}, 1),
+		archives: make(map[string]*watchedArchive),
+		loopDone: make(chan struct{}),
+	}
+}
+
+// Watch 开始监听稿件，重复监听同一个稿件会被忽略
+// id 视频ID av号或者bv号
+func (w *ArchiveWatcher) Watch(ids ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed || w.stopped {
+		return
+	}
+	for _, id := range ids {
+		if _, ok := w.archives[id]; ok {
+			continue
+		}
+		w.archives[id] = &watchedArchive{done: make(chan struct{})}
+		w.order = append(w.order, id)
+	}
+
+	if !w.running {
+		w.running = true
+		go w.run()
+	}
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run 轮询所有未到达最终状态的稿件，任意稿件状态变化时重置轮询间隔
+func (w *ArchiveWatcher) run() {
+	defer close(w.loopDone)
+	defer w.stop()
+
+	interval := w.policy.Interval
+	for {
+		// 没有需要轮询的稿件时只等待 Watch
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if pending := w.pending(); len(pending) > 0 {
+			changed, ok := w.poll(pending)
+			if !ok {
+				return
+			}
+			if changed {
+				interval = w.policy.Interval
+			}
+			timer = time.NewTimer(interval)
+			timeout = timer.C
+		}
+
+		select {
+		case <-w.ctx.Done():
+		case <-w.wake:
+		case <-timeout:
+			interval = w.policy.nextInterval(interval)
+			continue
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if w.ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// pending 还没有到达最终状态的稿件
+func (w *ArchiveWatcher) pending() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var ids []string
+	for _, id := range w.order {
+		if w.archives[id].final == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// poll 轮询一次，返回是否有稿件状态变化，ctx 结束时 ok 为 false
+func (w *ArchiveWatcher) poll(ids []string) (changed bool, ok bool) {
+	reviewing := make(map[string]*ArchiveReview)
+	it := w.client.CreatorArchives(w.ctx, ArchiveListReviewing)
+	for it.Next() {
+		review := creatorArchiveReview(it.Archive())
+		reviewing[review.Bvid] = review
+		reviewing["av"+strconv.FormatInt(review.Aid, 10)] = review
+	}
+	if w.ctx.Err() != nil {
+		return false, false
+	}
+	if err := it.Err(); err != nil {
+		w.client.log(w.ctx, SubsystemUpload, LevelWarn, "GetCreatorArchives failed", KV(FieldError, err))
+		return false, true
+	}
+
+	for _, id := range ids {
+		review, found := reviewing[normalizeArchiveID(id)]
+		if !found {
+			var err error
+			review, err = w.client.GetArchiveReview(w.ctx, id)
+			switch {
+			case w.ctx.Err() != nil:
+				return changed, false
+			case isFinalWatchError(err):
+				if !w.update(ArchiveEvent{ID: id, Type: ArchiveWatchFailed, Err: err}) {
+					return changed, false
+				}
+				changed = true
+				continue
+			case err != nil:
+				w.client.log(w.ctx, SubsystemUpload, LevelWarn, "GetArchiveReview failed", KV("id", id), KV(FieldError, err))
+				continue
+			}
+		}
+
+		event := archiveEvent(id, review)
+		w.mu.Lock()
+		last := w.archives[id].last
+		w.mu.Unlock()
+		if last != nil && last.Type == event.Type && last.Reason == event.Reason {
+			continue
+		}
+		if !w.update(event) {
+			return changed, false
+		}
+		changed = true
+	}
+
+	return changed, true
+}
+
+// update 发送事件并记录稿件状态，ctx 结束时返回 false
+func (w *ArchiveWatcher) update(event ArchiveEvent) bool {
+	select {
+	case w.events <- event:
+	case <-w.ctx.Done():
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	archive := w.archives[event.ID]
+	archive.last = &event
+	if event.Type.Final() {
+		archive.final = &event
+		close(archive.done)
+	}
+
+	return true
+}
+
+// stop 结束轮询，未到达最终状态的稿件不再等待
+func (w *ArchiveWatcher) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopped = true
+	for _, archive := range w.archives {
+		if archive.final == nil {
+			close(archive.done)
+		}
+	}
+}
+
+// normalizeArchiveID 将av号或者bv号转为列表中使用的格式，bv号为 BV 开头，av号为 av 开头
+func normalizeArchiveID(id string) string {
+	lower := strings.ToLower(id)
+	if strings.HasPrefix(lower, "bv") {
+		return "BV" + id[2:]
+	}
+
+	return "av" + strings.TrimPrefix(lower, "av")
+}
+
+// Events 稿件状态变化事件，Close 后关闭
+func (w *ArchiveWatcher) Events() <-chan ArchiveEvent {
+	return w.events
+}
+
+// Result 稿件的最终事件，还没有到达最终状态时返回 false
+func (w *ArchiveWatcher) Result(id string) (*ArchiveEvent, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	archive, ok := w.archives[id]
+	if !ok || archive.final == nil {
+		return nil, false
+	}
+
+	return archive.final, true
+}
+
+// Wait 等待当前所有稿件到达最终状态，ctx 结束时返回 ctx.Err()；
+// 监听停止时还有稿件没有到达最终状态，Close 后返回 ErrWatcherClosed，否则返回创建监听时 ctx 的错误
+func (w *ArchiveWatcher) Wait(ctx context.Context) error {
+	w.mu.Lock()
+	done := make([]chan struct{}, 0, len(w.archives))
+	for _, archive := range w.archives {
+		done = append(done, archive.done)
+	}
+	w.mu.Unlock()
+
+	for _, each := range done {
+		select {
+		case <-each:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, archive := range w.archives {
+		if archive.final != nil {
+			continue
+		}
+		if w.closed {
+			return ErrWatcherClosed
+		}
+		return w.ctx.Err()
+	}
+
+	return nil
+}
+
+// Close 停止所有监听并关闭 Events
+func (w *ArchiveWatcher) Close() {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.closed = true
+	running := w.running
+	w.mu.Unlock()
+
+	w.cancel()
+	if running {
+		<-w.loopDone
+	}
+	close(w.events)
+}
@@ -0,0 +1,239 @@
+package bilibili_go
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reviewServer 每次请求按顺序返回 states 中的下一个状态，最后一个状态保持不变，
+// 状态为412时返回 -412 风控，其他正数返回网络错误
+func reviewServer(states map[string][]int) http.Handler {
+	var mu sync.Mutex
+	calls := make(map[string]int)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("bvid")
+		mu.Lock()
+		list, ok := states[id]
+		n := calls[id]
+		calls[id]++
+		mu.Unlock()
+
+		if !ok {
+			_, _ = w.Write([]byte(`{"code":-404,"message":"啥都木有"}`))
+			return
+		}
+		if n >= len(list) {
+			n = len(list) - 1
+		}
+		if list[n] == 412 {
+			_, _ = w.Write([]byte(`{"code":-412,"message":"请求被拦截"}`))
+			return
+		}
+		if list[n] > 0 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"code":0,"data":{"archive":{"aid":1,"bvid":%q,"state":%d},`+
+			`"videos":[{"cid":10,"reject_reason":"画面违规"}]}}`, id, list[n])
+	})
+}
+
+func TestWaitArchive(t *testing.T) {
+	client := newTestClient(t, reviewServer(map[string][]int{"BV1": {-30, 1, 412, -1, -1, 0}}))
+	policy := WatchPolicy{Interval: time.Millisecond, MaxInterval: 4 * time.Millisecond}
+
+	event, err := client.WaitArchive(context.Background(), "BV1", policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != ArchivePublished || event.Review.Bvid != "BV1" {
+		t.Errorf("WaitArchive() = %+v", event)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	client = newTestClient(t, reviewServer(map[string][]int{"BV1": {-1}}))
+	if _, err := client.WaitArchive(ctx, "BV1", policy); err != context.DeadlineExceeded {
+		t.Errorf("WaitArchive() error = %v, want deadline exceeded", err)
+	}
+}
+
+// archiveListServer 模拟创作中心稿件列表，每次获取列表为一轮，稿件状态为 states 中该轮的状态，最后一个状态保持不变，
+// 列表中只返回审核中的稿件，其他稿件需要单独获取状态；viewErrors 中的稿件第一次单独获取状态时返回对应的业务码
+type archiveListServer struct {
+	mu         sync.Mutex
+	states     map[string][]int
+	viewErrors map[string]int
+	rounds     int
+	lists      int
+	views      map[string]int
+}
+
+func (s *archiveListServer) state(bvid string) (int, bool) {
+	list, ok := s.states[bvid]
+	if !ok {
+		return 0, false
+	}
+	n := s.rounds - 1
+	if n >= len(list) {
+		n = len(list) - 1
+	}
+
+	return list[n], true
+}
+
+func (s *archiveListServer) item(bvid string, state int) string {
+	return fmt.Sprintf(`{"aid":%s,"bvid":%q,"state":%d}`, strings.TrimPrefix(bvid, "BV"), bvid, state)
+}
+
+func (s *archiveListServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/x/web/archives":
+		if r.URL.Query().Get("status") != string(ArchiveListReviewing) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.rounds++
+		s.lists++
+		// 第二轮获取列表失败，下一轮重试
+		if s.lists == 2 {
+			s.rounds--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		var items []string
+		for bvid := range s.states {
+			if state, _ := s.state(bvid); ArchiveState(state).IsReviewing() {
+				items = append(items, `{"Archive":`+s.item(bvid, state)+`,"Videos":[]}`)
+			}
+		}
+		_, _ = fmt.Fprintf(w, `{"code":0,"data":{"arc_audits":[%s],"page":{"pn":1,"ps":20,"count":%d}}}`, strings.Join(items, ","), len(items))
+	case "/x/vupre/web/archive/view":
+		bvid := r.URL.Query().Get("bvid")
+		if aid := r.URL.Query().Get("aid"); aid != "" {
+			bvid = "BV" + aid
+		}
+		s.views[bvid]++
+		if code, ok := s.viewErrors[bvid]; ok && s.views[bvid] == 1 {
+			_, _ = fmt.Fprintf(w, `{"code":%d,"message":"error"}`, code)
+			return
+		}
+		state, ok := s.state(bvid)
+		if !ok {
+			_, _ = w.Write([]byte(`{"code":-404,"message":"啥都木有"}`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"code":0,"data":{"archive":%s,"videos":[{"cid":10,"reject_reason":"画面违规"}]}}`, s.item(bvid, state))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestArchiveWatcher(t *testing.T) {
+	server := &archiveListServer{
+		states: map[string][]int{
+			"BV1": {-30, -9, -1, -1, 0},
+			"BV2": {-1, -2},
+			"BV3": {-4},
+			"BV5": {-1, 0},
+		},
+		viewErrors: map[string]int{"BV5": -412},
+		views:      make(map[string]int),
+	}
+	client := newTestClient(t, server)
+
+	watcher := client.NewArchiveWatcher(context.Background(), WatchPolicy{Interval: time.Millisecond, MaxInterval: 4 * time.Millisecond})
+	watcher.Watch("BV1", "BV2", "av3", "BV4", "BV1", "bv5")
+
+	events := make(map[string][]ArchiveEventType)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range watcher.Events() {
+			events[event.ID] = append(events[event.ID], event.Type)
+			if event.Type == ArchiveRejected && event.Reason != "画面违规" {
+				t.Errorf("rejected event = %+v", event)
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := watcher.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	watcher.Close()
+	<-done
+
+	want := map[string][]ArchiveEventType{
+		"BV1": {ArchiveTranscoding, ArchiveReviewing, ArchivePublished},
+		"BV2": {ArchiveReviewing, ArchiveRejected},
+		"av3": {ArchiveLocked},
+		"BV4": {ArchiveWatchFailed},
+		"bv5": {ArchiveReviewing, ArchivePublished}, // -412 不会结束监听
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %v, want %v", events, want)
+	}
+	if final, ok := watcher.Result("BV2"); !ok || final.Type != ArchiveRejected {
+		t.Errorf("Result(BV2) = %+v, %v", final, ok)
+	}
+
+	// 审核中的稿件从列表中获取状态，只有到达最终状态时才单独获取
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.views["BV1"] != 1 || server.views["BV2"] != 1 || server.views["BV3"] != 1 {
+		t.Errorf("views = %v, want one view per archive with a final state", server.views)
+	}
+}
+
+func TestArchiveWatcherClose(t *testing.T) {
+	server := &archiveListServer{states: map[string][]int{"BV1": {-1}}, views: make(map[string]int)}
+	client := newTestClient(t, server)
+
+	watcher := client.NewArchiveWatcher(context.Background(), WatchPolicy{Interval: time.Millisecond})
+	watcher.Watch("BV1")
+	go func() {
+		for range watcher.Events() {
+		}
+	}()
+
+	errc := make(chan error, 1)
+	go func() { errc <- watcher.Wait(context.Background()) }()
+	time.Sleep(10 * time.Millisecond)
+	watcher.Close()
+
+	select {
+	case err := <-errc:
+		if err != ErrWatcherClosed {
+			t.Errorf("Wait() error = %v, want ErrWatcherClosed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait() did not return after Close")
+	}
+
+	// 创建监听时的 ctx 结束
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := client.NewArchiveWatcher(ctx, WatchPolicy{Interval: time.Millisecond})
+	defer canceled.Close()
+	canceled.Watch("BV1")
+	go func() {
+		for range canceled.Events() {
+		}
+	}()
+	cancel()
+	if err := canceled.Wait(context.Background()); err != context.Canceled {
+		t.Errorf("Wait() error = %v, want context.Canceled", err)
+	}
+}
@@ -60,6 +60,33 @@ func (f fileAuthStorage) LogoutAuthInfo(*AuthInfo) error {
 	return os.Remove(f.file)
 }
 
+// LoadWbiKey wbi key 保存在 file + ".wbi" 中
+func (f fileAuthStorage) LoadWbiKey() (*WbiKey, error) {
+	bts, err := os.ReadFile(f.file + ".wbi")
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var key WbiKey
+	if err = json.Unmarshal(bts, &key); err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (f fileAuthStorage) SaveWbiKey(key *WbiKey) error {
+	bts, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(f.file+".wbi", bts, 0644)
+}
+
 func NewFileAuthStorage(file string) AuthStorage {
 	return &fileAuthStorage{file: file}
 }
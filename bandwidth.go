@@ -0,0 +1,99 @@
+package bilibili_go
+
+import (
+	"context"
+	"github.com/kainhuck/bilibili-go/internal/utils"
+	"io"
+	"net/http"
+)
+
+// bandwidthBurst 带宽限制允许的突发字节数
+const bandwidthBurst = 64 * utils.KB
+
+// BandwidthLimiter 上传带宽限制，可以在上传过程中通过 SetLimit 修改，多个上传共用时限制它们的总带宽
+type BandwidthLimiter struct {
+	limiter *limiter
+}
+
+// NewBandwidthLimiter 创建带宽限制，bytesPerSecond 为每秒字节数，不大于0时不限制
+func NewBandwidthLimiter(bytesPerSecond int64) *BandwidthLimiter {
+	return &BandwidthLimiter{limiter: newLimiter(float64(bytesPerSecond), bandwidthBurst)}
+}
+
+// SetLimit 修改带宽限制，立即对正在上传的分片生效，不大于0时不限制
+func (b *BandwidthLimiter) SetLimit(bytesPerSecond int64) {
+	b.limiter.SetRate(float64(bytesPerSecond))
+}
+
+// Limit 当前的带宽限制
+func (b *BandwidthLimiter) Limit() int64 {
+	b.limiter.mu.Lock()
+	defer b.limiter.mu.Unlock()
+
+	return int64(b.limiter.rate)
+}
+
+// SetUploadBandwidth 修改 Client 所有上传的总带宽限制，立即对正在上传的分片生效，不大于0时不限制
+func (c *Client) SetUploadBandwidth(bytesPerSecond int64) {
+	c.uploadBandwidth.SetLimit(bytesPerSecond)
+}
+
+type bandwidthLimiterKey struct{}
+
+func withBandwidthLimiter(ctx context.Context, limiter *BandwidthLimiter) context.Context {
+	if limiter == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, bandwidthLimiterKey{}, limiter)
+}
+
+// throttledReader 读取后等待令牌，同时受所有 limiters 限制
+type throttledReader struct {
+	io.ReadCloser
+	ctx      context.Context
+	limiters []*BandwidthLimiter
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	// 每次最多读取约100毫秒的数据，修改限制后很快生效
+	for _, limiter := range r.limiters {
+		if limit := limiter.Limit(); limit > 0 {
+			if max := limit/10 + 1; int64(len(p)) > max {
+				p = p[:max]
+			}
+		}
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		for _, limiter := range r.limiters {
+			if waitErr := limiter.limiter.WaitN(r.ctx, n); waitErr != nil {
+				return n, waitErr
+			}
+		}
+	}
+
+	return n, err
+}
+
+// uploadBandwidthMiddleware 限制分片上传请求体的发送速度，位于进度统计中间件外层，进度按限速后的字节统计
+func (c *Client) uploadBandwidthMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			if request.Method != http.MethodPut || Endpoint(request.URL) != "upos" || request.Body == nil || request.Body == http.NoBody {
+				return next(request)
+			}
+
+			limiters := []*BandwidthLimiter{c.uploadBandwidth}
+			if limiter, ok := request.Context().Value(bandwidthLimiterKey{}).(*BandwidthLimiter); ok {
+				limiters = append(limiters, limiter)
+			}
+
+			throttled := request.Clone(request.Context())
+			throttled.Body = &throttledReader{ReadCloser: request.Body, ctx: request.Context(), limiters: limiters}
+
+			return next(throttled)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestUploadBandwidth(t *testing.T) {
+	content := testVideo(3*testChunkSize + 1)
+
+	// Client 总带宽 4MB/s，约需200毫秒
+	server := newUploadServer()
+	client := newTestClient(t, server, WithUploadBandwidth(4<<20))
+	start := time.Now()
+	if _, err := client.UploadVideo("test.mp4", content); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("upload took %v, want throttled", elapsed)
+	}
+	if !bytes.Equal(server.content(), content) {
+		t.Error("content mismatch")
+	}
+
+	// 上传过程中取消限制，64KB/s 需要12秒
+	server = newUploadServer()
+	client = newTestClient(t, server)
+	limiter := NewBandwidthLimiter(64 << 10)
+	start = time.Now()
+	_, err := client.UploadVideoContext(context.Background(), "test.mp4", content, WithBandwidthLimiter(limiter),
+		WithProgressInterval(10*time.Millisecond), WithProgress(func(p UploadProgress) {
+			if p.SentBytes > 0 {
+				limiter.SetLimit(0)
+			}
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("upload took %v after limit removed", elapsed)
+	}
+	if limiter.Limit() != 0 || !bytes.Equal(server.content(), content) {
+		t.Error("limit not removed or content mismatch")
+	}
+
+	client.SetUploadBandwidth(1 << 20)
+	if client.uploadBandwidth.Limit() != 1<<20 {
+		t.Errorf("client bandwidth = %d", client.uploadBandwidth.Limit())
+	}
+}
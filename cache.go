@@ -0,0 +1,205 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache 接口响应缓存，可以自行实现对接 redis 等存储
+type Cache interface {
+	// Get 获取缓存，不存在或已过期返回false
+	Get(key string) ([]byte, bool)
+
+	// Set 设置缓存，ttl 为过期时间
+	Set(key string, value []byte, ttl time.Duration)
+
+	// DeletePrefix 删除所有以 prefix 开头的缓存
+	DeletePrefix(prefix string)
+}
+
+// DefaultCacheTTLs 默认缓存的接口及缓存时间，key 为接口名称（见 Endpoint）
+var DefaultCacheTTLs = map[string]time.Duration{
+	"api.bilibili.com/x/space/wbi/acc/info":             5 * time.Minute,  // GetUserInfo
+	"api.bilibili.com/x/web-interface/card":             5 * time.Minute,  // GetUserCard
+	"api.bilibili.com/x/web-interface/ranking/v2":       10 * time.Minute, // GetVideoRank
+	"api.bilibili.com/x/web-interface/popular/precious": 30 * time.Minute, // GetPreciousVideo
+	"api.bilibili.com/x/relation/stat":                  time.Minute,      // GetRelationStat
+	"api.bilibili.com/x/web-interface/nav":              time.Minute,      // GetNavigation
+}
+
+// 关系变更后需要失效的接口
+var relationEndpoints = []string{
+	"api.bilibili.com/x/relation/stat",
+	"api.bilibili.com/x/relation",
+	"api.bilibili.com/x/relation/relations",
+	"api.bilibili.com/x/relation/followings",
+	"api.bilibili.com/x/relation/followers",
+	"api.bilibili.com/x/space/wbi/acc/relation",
+	"api.bilibili.com/x/space/wbi/acc/info",
+	"api.bilibili.com/x/web-interface/card",
+	"api.bilibili.com/x/web-interface/nav/stat",
+}
+
+// 生成缓存key时忽略的参数
+var cacheIgnoredParams = map[string]bool{
+	"wts":   true,
+	"w_rid": true,
+}
+
+// responseCache 只读接口的缓存
+type responseCache struct {
+	cache Cache
+	ttls  map[string]time.Duration
+}
+
+// cacheKey 缓存key 格式为 mid:endpoint?query，不同账号的缓存互不影响
+func cacheKey(mid int64, endpoint string, query url.Values) string {
+	return fmt.Sprintf("%d:%s?%s", mid, endpoint, normalizeQuery(query, cacheIgnoredParams))
+}
+
+// cacheMiddleware 缓存 GET 请求中成功的响应
+func (c *Client) cacheMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			endpoint := Endpoint(request.URL)
+			ttl, ok := c.cache.ttls[endpoint]
+			if !ok || request.Method != http.MethodGet {
+				return next(request)
+			}
+
+			key := cacheKey(c.getMid(), endpoint, request.URL.Query())
+			if body, ok := c.cache.cache.Get(key); ok {
+				return &http.Response{
+					Status:        "200 OK",
+					StatusCode:    http.StatusOK,
+					Proto:         "HTTP/1.1",
+					ProtoMajor:    1,
+					ProtoMinor:    1,
+					Header:        http.Header{"Content-Type": []string{"application/json"}},
+					Body:          io.NopCloser(bytes.NewReader(body)),
+					ContentLength: int64(len(body)),
+					Request:       request,
+				}, nil
+			}
+
+			resp, err := next(request)
+			if err != nil || resp.StatusCode != http.StatusOK {
+				return resp, err
+			}
+
+			code, err := peekCode(resp)
+			if err != nil {
+				return nil, err
+			}
+			if code != nil && *code == CodeSuccess {
+				body, err := io.ReadAll(resp.Body)
+				if err != nil {
+					return nil, err
+				}
+				resp.Body = io.NopCloser(bytes.NewReader(body))
+				c.cache.cache.Set(key, body, ttl)
+			}
+
+			return resp, nil
+		}
+	}
+}
+
+// InvalidateCache 使当前账号下指定接口的缓存失效，endpoints 为接口名称（见 Endpoint），不指定则清空所有缓存
+func (c *Client) InvalidateCache(endpoints ...string) {
+	if c.cache == nil {
+		return
+	}
+
+	if len(endpoints) == 0 {
+		c.cache.cache.DeletePrefix("")
+		return
+	}
+
+	for _, endpoint := range endpoints {
+		c.cache.cache.DeletePrefix(fmt.Sprintf("%d:%s?", c.getMid(), endpoint))
+	}
+}
+
+// lruCache 基于 LRU 淘汰的内存缓存
+type lruCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // 最近使用的在前
+}
+
+type lruItem struct {
+	key      string
+	value    []byte
+	expireAt time.Time
+}
+
+// NewLRUCache 创建内存缓存，最多缓存 capacity 条，超出后淘汰最久未使用的
+func NewLRUCache(capacity int) Cache {
+	return &lruCache{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (l *lruCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := elem.Value.(*lruItem)
+	if time.Now().After(item.expireAt) {
+		l.remove(elem)
+		return nil, false
+	}
+	l.order.MoveToFront(elem)
+
+	return item.value, true
+}
+
+func (l *lruCache) Set(key string, value []byte, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.items[key]; ok {
+		item := elem.Value.(*lruItem)
+		item.value = value
+		item.expireAt = time.Now().Add(ttl)
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&lruItem{key: key, value: value, expireAt: time.Now().Add(ttl)})
+	for l.capacity > 0 && l.order.Len() > l.capacity {
+		l.remove(l.order.Back())
+	}
+}
+
+func (l *lruCache) DeletePrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, elem := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.remove(elem)
+		}
+	}
+}
+
+func (l *lruCache) remove(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.items, elem.Value.(*lruItem).key)
+}
@@ -0,0 +1,98 @@
+package bilibili_go
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	a, _ := url.ParseQuery("mid=2&wts=1700000000&w_rid=aaa")
+	b, _ := url.ParseQuery("w_rid=bbb&wts=1700000001&mid=2")
+
+	if cacheKey(1, "api.bilibili.com/x/space/wbi/acc/info", a) != cacheKey(1, "api.bilibili.com/x/space/wbi/acc/info", b) {
+		t.Fatal("wbi params should be ignored")
+	}
+	if cacheKey(1, "api.bilibili.com/x/space/wbi/acc/info", a) == cacheKey(2, "api.bilibili.com/x/space/wbi/acc/info", a) {
+		t.Fatal("different accounts should not share cache")
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("a"), time.Minute)
+	cache.Set("b", []byte("b"), time.Minute)
+	cache.Get("a")
+	cache.Set("c", []byte("c"), time.Minute)
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("least recently used entry should be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("recently used entry should be kept")
+	}
+
+	cache.Set("d", []byte("d"), -time.Second)
+	if _, ok := cache.Get("d"); ok {
+		t.Error("expired entry should not be returned")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	var calls int
+	client := newTestClient(t, testAPIHandler(&calls), WithCache(NewLRUCache(10), nil))
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetRelationStat(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+
+	client.InvalidateCache(relationEndpoints...)
+	if _, err := client.GetRelationStat(2); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatalf("calls = %d after invalidation, want 2", calls)
+	}
+}
+
+func TestModifyRelationInvalidatesCache(t *testing.T) {
+	var calls int
+	client := newTestClient(t, testAPIHandler(&calls), WithCache(NewLRUCache(10), nil))
+	client.setMid(1)
+
+	fetch := func() {
+		t.Helper()
+		if _, err := client.GetUserInfo(2); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.GetRelationStat(2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fetch()
+	warm := calls
+	fetch()
+	if calls != warm {
+		t.Fatalf("calls = %d, want cached %d", calls, warm)
+	}
+
+	if err := client.ModifyRelation(2, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	fetch()
+	if calls != warm+3 {
+		t.Errorf("calls = %d after ModifyRelation, want %d", calls, warm+3)
+	}
+
+	// 退出登录后 mid 重置，不会读到之前账号的缓存
+	client.setAuthInfo(nil)
+	if mid := client.getMid(); mid != 0 {
+		t.Errorf("mid = %d after logout, want 0", mid)
+	}
+}
@@ -0,0 +1,139 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+// Request 自定义请求，用于调用尚未封装的接口
+type Request struct {
+	// Method 默认为 GET
+	Method string
+
+	// URL 接口地址
+	URL string
+
+	// Params 查询参数
+	Params url.Values
+
+	// Form 表单数据，不为空时以 application/x-www-form-urlencoded 发送
+	Form url.Values
+
+	// JSON 不为nil时序列化为json发送
+	JSON any
+
+	// Header 请求头
+	Header map[string]string
+
+	// CSRF 是否注入 csrf 参数，表单请求放在表单中，否则放在查询参数中
+	CSRF bool
+
+	// Wbi 是否进行 wbi 签名
+	Wbi bool
+
+	// Anonymous 不携带cookie
+	Anonymous bool
+
+	// Retry 按 RetryPolicy 重试非 GET 请求，只应该用于重复提交没有副作用的接口，GET 请求总是会重试
+	Retry bool
+}
+
+// Call 调用任意接口，与内置接口一样使用客户端的cookie、中间件、重试和限流等配置，
+// 业务码不为 CodeSuccess 时同时返回响应和 *APIError
+func (c *Client) Call(ctx context.Context, req Request) (*BaseResponse, error) {
+	raw, err := c.call(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	baseResp, err := toBaseResponse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if baseResp.Code != CodeSuccess {
+		return baseResp, &APIError{BaseResponse: *baseResp}
+	}
+
+	return baseResp, nil
+}
+
+// CallInto 同 Call，将 data 直接解析为 T
+func CallInto[T any](ctx context.Context, c *Client, req Request) (*T, error) {
+	raw, err := c.call(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if raw.Code != CodeSuccess {
+		baseResp, err := toBaseResponse(raw)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &APIError{BaseResponse: *baseResp}
+	}
+
+	rsp := new(T)
+	err = json.Unmarshal(raw.Data, rsp)
+
+	return rsp, err
+}
+
+// call 发送请求，data 保留为原始json
+func (c *Client) call(ctx context.Context, req Request) (*rawResponse, error) {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	if req.Retry {
+		ctx = withRetry(ctx)
+	}
+	client := c.getHttpClient(!req.Anonymous).SetContext(ctx).Method(method, req.URL)
+
+	for key, values := range req.Params {
+		for _, value := range values {
+			client.AddParams(key, value)
+		}
+	}
+	for key, values := range req.Form {
+		for _, value := range values {
+			client.AddFormData(key, value)
+		}
+	}
+	for key, value := range req.Header {
+		client.SetHeader(key, value)
+	}
+
+	if req.JSON != nil {
+		bts, err := json.Marshal(req.JSON)
+		if err != nil {
+			return nil, err
+		}
+		client.SetContentType("application/json;charset=UTF-8").SendBody(bytes.NewReader(bts))
+	}
+
+	if req.CSRF {
+		if csrf := c.getCSRF(); len(req.Form) > 0 {
+			client.AddFormData("csrf", csrf)
+		} else {
+			client.AddParams("csrf", csrf)
+		}
+	}
+
+	if req.Wbi {
+		wbiKey, err := c.getWbiKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+		client.SetWbiKey(wbiKey)
+	}
+
+	var raw rawResponse
+	if err := client.EndStruct(&raw); err != nil {
+		return nil, err
+	}
+
+	return &raw, nil
+}
@@ -0,0 +1,95 @@
+package bilibili_go
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestCall(t *testing.T) {
+	var calls int
+	client := newTestClient(t, testAPIHandler(&calls))
+	ctx := context.Background()
+
+	t.Run("wbi", func(t *testing.T) {
+		type userInfo struct {
+			Mid  int64  `json:"mid"`
+			Name string `json:"name"`
+		}
+		info, err := CallInto[userInfo](ctx, client, Request{
+			URL:    "https://api.bilibili.com/x/space/wbi/acc/info",
+			Params: url.Values{"mid": {"2"}},
+			Wbi:    true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mid != 2 || info.Name != "test" {
+			t.Errorf("info = %+v", info)
+		}
+	})
+
+	t.Run("api error", func(t *testing.T) {
+		resp, err := client.Call(ctx, Request{
+			URL:    "https://api.bilibili.com/x/space/wbi/acc/info",
+			Params: url.Values{"mid": {"2"}},
+		})
+		if !IsCode(err, -352) {
+			t.Fatalf("err = %v, want code -352", err)
+		}
+		if resp == nil || resp.Message == "" {
+			t.Errorf("resp = %+v, want response with message", resp)
+		}
+	})
+}
+
+func TestRetry(t *testing.T) {
+	var calls int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		case 2:
+			_, _ = w.Write([]byte(`{"code":-799,"message":"请求过于频繁，请稍后再试"}`))
+		default:
+			_ = r.ParseForm()
+			_, _ = w.Write([]byte(`{"code":0,"data":{"csrf":"` + r.PostForm.Get("csrf") + `"}}`))
+		}
+	})
+	client := newTestClient(t, handler, WithRetry(RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}))
+	client.csrf = "token"
+
+	resp, err := CallInto[map[string]string](context.Background(), client, Request{
+		Method: http.MethodPost,
+		URL:    "https://api.bilibili.com/x/relation/modify",
+		Form:   url.Values{"fid": {"2"}},
+		CSRF:   true,
+		Retry:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if (*resp)["csrf"] != "token" {
+		t.Errorf("csrf = %q, want replayed form body", (*resp)["csrf"])
+	}
+
+	// 非幂等请求默认不重试，视频分片上传不重试
+	for _, req := range []Request{
+		{Method: http.MethodPost, URL: "https://api.bilibili.com/x/relation/modify", Form: url.Values{"fid": {"2"}}},
+		{Method: http.MethodPut, URL: "https://upos-cs-upcdnbda2.bilivideo.com/video.mp4", Retry: true},
+	} {
+		calls = 0
+		if _, err := client.Call(context.Background(), req); err == nil {
+			t.Errorf("%s %s should fail", req.Method, req.URL)
+		}
+		if calls != 1 {
+			t.Errorf("%s %s calls = %d, want 1", req.Method, req.URL, calls)
+		}
+	}
+}
@@ -1,49 +1,135 @@
 package bilibili_go
 
 import (
-	"fmt"
+	"bytes"
+	"context"
+	"errors"
 	"github.com/kainhuck/bilibili-go/internal/net"
-	"github.com/kainhuck/bilibili-go/internal/utils"
 	"github.com/skip2/go-qrcode"
 	"io"
 	"net/http"
 	"os"
-	"strings"
 	"sync"
 	"time"
 )
 
 type debugInfo struct {
 	debug  bool
-	output *os.File
+	output io.Writer
+	closer io.Closer // 由配置文件打开的输出文件，Client.Close 时关闭
 }
 
 type Client struct {
-	httpClient       *net.HttpClient
-	authInfo         *AuthInfo
-	authStorage      AuthStorage
-	csrf             string
-	wbiKey           string // imgKey + subKey
-	wbiKeyLastUpdate time.Time
-	debug            *debugInfo
-	logger           Logger
-	showQRCodeFunc   func(code *qrcode.QRCode) error
-	mid              int64 // 当前用户mid
-	intervalMutex    sync.Mutex
+	httpClient      *net.HttpClient // 不携带cookie
+	authHttpClient  *net.HttpClient // 通过 jar 携带cookie
+	jar             *cookieJar
+	authInfo        *AuthInfo
+	authMutex       sync.Mutex
+	authStorage     AuthStorage
+	csrf            string
+	wbiKey          *WbiKey
+	wbiMutex        sync.Mutex
+	harWriter       *HarWriter // 调试模式下记录http报文
+	debugCloser     io.Closer
+	closeOnce       sync.Once
+	closeErr        error
+	tracer          Tracer
+	recorder        *recorder
+	metrics         Metrics
+	cache           *responseCache
+	proxyPool       *ProxyPool
+	headerProfiles  map[string]HeaderProfile
+	userAgent       string // 从 User-Agent 池中选出，整个 Client 固定使用，登录前后不变
+	retryPolicy     RetryPolicy
+	rateLimiter     *limiter
+	uploadSessions  UploadSessionStore
+	uploadBandwidth *BandwidthLimiter
+	// 默认的上传线路以及是否测速
+	defaultUploadLines []UploadLine
+	probeUploadLines   bool
+	logger             StructuredLogger
+	logLevels          map[Subsystem]LogLevel
+	showQRCodeFunc     func(code *qrcode.QRCode) error
+	mid                int64 // 当前用户mid，与 authInfo 一样由 authMutex 保护
+	intervalMutex      sync.Mutex
 }
 
 func NewClient(opts ...Option) *Client {
 	opt := applyOptions(opts...)
 
-	httpClient := net.NewHttpClient(opt.HttpClient).SetUserAgent(opt.UserAgent)
+	// 自定义的 RoundTripper 无法设置代理，记录错误并关闭代理池
+	var proxyErr error
+	if opt.ProxyPool != nil {
+		proxyClient, err := withProxyTransport(opt.HttpClient)
+		if err != nil {
+			proxyErr = err
+			opt.ProxyPool = nil
+		} else {
+			opt.HttpClient = proxyClient
+		}
+	}
+
+	httpClient := net.NewHttpClient(opt.HttpClient).
+		SetUserAgent(opt.UserAgent).
+		Use(opt.Middlewares...)
 
 	client := &Client{
-		httpClient:     httpClient,
-		authStorage:    opt.AuthStorage,
-		debug:          opt.Debug,
-		logger:         opt.Logger,
-		showQRCodeFunc: opt.ShowQRCodeFunc,
-		intervalMutex:  sync.Mutex{},
+		httpClient:         httpClient,
+		authStorage:        opt.AuthStorage,
+		logger:             opt.Logger,
+		logLevels:          opt.LogLevels,
+		showQRCodeFunc:     opt.ShowQRCodeFunc,
+		metrics:            opt.Metrics,
+		tracer:             opt.Tracer,
+		cache:              opt.Cache,
+		proxyPool:          opt.ProxyPool,
+		headerProfiles:     make(map[string]HeaderProfile),
+		userAgent:          pickUserAgent(opt.UserAgents),
+		retryPolicy:        opt.RetryPolicy,
+		rateLimiter:        opt.RateLimiter,
+		uploadSessions:     opt.UploadSessionStore,
+		uploadBandwidth:    NewBandwidthLimiter(opt.UploadBandwidth),
+		defaultUploadLines: opt.UploadLines,
+		probeUploadLines:   opt.ProbeUploadLines,
+		intervalMutex:      sync.Mutex{},
+	}
+
+	for prefix, profile := range DefaultHeaderProfiles {
+		client.headerProfiles[prefix] = profile
+	}
+	for prefix, profile := range opt.HeaderProfiles {
+		client.headerProfiles[prefix] = profile
+	}
+
+	client.jar = newCookieJar(client.mergeCookies)
+	authHttpClient := *opt.HttpClient
+	authHttpClient.Jar = responseJar{client.jar}
+	client.authHttpClient = net.NewHttpClient(&authHttpClient).
+		SetUserAgent(opt.UserAgent).
+		Use(client.cookieMiddleware()).
+		Use(opt.Middlewares...)
+
+	if proxyErr != nil {
+		client.log(context.Background(), SubsystemHTTP, LevelError, "proxy pool disabled", KV(FieldError, proxyErr))
+	}
+
+	if opt.Recorder != nil {
+		client.recorder = newRecorder(opt.Recorder.path, opt.Recorder.mode)
+		client.recorder.onError(func(err error) {
+			client.log(context.Background(), SubsystemHTTP, LevelError, "write recorder file failed", KV(FieldError, err))
+		})
+	}
+
+	if opt.Debug.debug {
+		output := opt.Debug.output
+		if output == nil {
+			output = os.Stdout
+		}
+		client.harWriter = NewHarWriter(output, opt.DebugOptions)
+		client.harWriter.onError = func(err error) {
+			client.log(context.Background(), SubsystemHTTP, LevelError, "write debug har failed", KV(FieldError, err))
+		}
+		client.debugCloser = opt.Debug.closer
 	}
 
 	if opt.RefreshInterval > 0 {
@@ -53,7 +139,7 @@ func NewClient(opts ...Option) *Client {
 				select {
 				case <-ticker.C:
 					if err := client.RefreshAuthInfo(); err != nil {
-						client.logger.Errorf("refresh auth info failed: %v", err)
+						client.log(context.Background(), SubsystemAuth, LevelError, "refresh auth info failed", KV(FieldError, err))
 					}
 				}
 			}
@@ -63,11 +149,42 @@ func NewClient(opts ...Option) *Client {
 	return client
 }
 
+// Close 补全调试模式 HAR 以及录制文件的结尾，关闭由配置文件打开的文件，返回写入失败的错误，之后不应再使用 Client
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		var errs []error
+		if c.harWriter != nil {
+			errs = append(errs, c.harWriter.Close())
+		}
+		if c.debugCloser != nil {
+			errs = append(errs, c.debugCloser.Close())
+		}
+		if c.recorder != nil {
+			errs = append(errs, c.recorder.close())
+		}
+		c.closeErr = errors.Join(errs...)
+	})
+
+	return c.closeErr
+}
+
 func (c *Client) setAuthInfo(auth *AuthInfo) {
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
+
 	c.authInfo = auth
 	if c.authInfo == nil {
+		c.csrf = ""
+		c.mid = 0
+		c.jar.reset(nil)
 		return
 	}
+	c.jar.reset(c.authInfo.Cookies)
+	c.updateCSRF()
+}
+
+// updateCSRF 从cookie中获取csrf，需持有 authMutex
+func (c *Client) updateCSRF() {
 	for _, cookie := range c.authInfo.Cookies {
 		if cookie.Name == "bili_jct" {
 			c.csrf = cookie.Value
@@ -75,84 +192,92 @@ func (c *Client) setAuthInfo(auth *AuthInfo) {
 	}
 }
 
-func (c *Client) getWbiKey() string {
-	resp, err := c.GetNavigation()
-	if err != nil {
-		return ""
-	}
+// getCSRF 当前的csrf
+func (c *Client) getCSRF() string {
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
 
-	imgKey := strings.Split(strings.Split(resp.WBIImg.ImgURL, "/")[len(strings.Split(resp.WBIImg.ImgURL, "/"))-1], ".")[0]
-	subKey := strings.Split(strings.Split(resp.WBIImg.SubURL, "/")[len(strings.Split(resp.WBIImg.SubURL, "/"))-1], ".")[0]
-	return imgKey + subKey
+	return c.csrf
 }
 
-func (c *Client) updateWbiKeyCache() {
-	if time.Since(c.wbiKeyLastUpdate).Minutes() < 10 {
-		return
-	}
+// getMid 当前用户mid，未登录时为0
+func (c *Client) getMid() int64 {
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
 
-	c.wbiKey = c.getWbiKey()
-	c.wbiKeyLastUpdate = time.Now()
+	return c.mid
 }
 
-func (c *Client) getWbiKeyCached() string {
-	c.updateWbiKeyCache()
+// setMid 登录成功后记录当前用户mid
+func (c *Client) setMid(mid int64) {
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
 
-	return c.wbiKey
+	c.mid = mid
+}
+
+// getAuthInfo 当前的认证信息，AuthInfo 只会被整体替换，返回值可以安全读取
+func (c *Client) getAuthInfo() *AuthInfo {
+	c.authMutex.Lock()
+	defer c.authMutex.Unlock()
+
+	return c.authInfo
 }
 
 /* ================= 一下是对接口的二次封装 ================= */
 
 // LoginWithQrCode 登陆这一步必须成功，否则后续接口无法访问
 func (c *Client) LoginWithQrCode() {
+	ctx := withRequestID(context.Background())
+
 	if c.authStorage != nil {
 		auth, err := c.authStorage.LoadAuthInfo()
 		if err == nil && auth != nil {
 			c.setAuthInfo(auth)
 			user, err := c.GetMyAccount()
 			if err == nil {
-				c.mid = user.Mid
-				c.logger.Info("load auth info from storage")
+				c.setMid(user.Mid)
+				c.log(ctx, SubsystemAuth, LevelInfo, "load auth info from storage")
 				return
 			} else {
 				// maybe token过期
-				c.logger.Warnf("auth info error: %v", err)
+				c.log(ctx, SubsystemAuth, LevelWarn, "auth info error", KV(FieldError, err))
 			}
 		}
 		if err != nil {
-			c.logger.Errorf("load auth info failed: %v", err)
+			c.log(ctx, SubsystemAuth, LevelError, "load auth info failed", KV(FieldError, err))
 		}
 	}
 
 	defer func() {
 		if c.authStorage != nil {
-			if err := c.authStorage.SaveAuthInfo(c.authInfo); err != nil {
-				c.logger.Errorf("SaveAuthInfo failed: %v", err)
+			if err := c.authStorage.SaveAuthInfo(c.getAuthInfo()); err != nil {
+				c.log(ctx, SubsystemAuth, LevelError, "SaveAuthInfo failed", KV(FieldError, err))
 			}
 		}
 	}()
 
 	generateResp, err := c.qrcodeGenerate()
 	if err != nil {
-		c.logger.Errorf("generate qrcode failed: %v", err)
+		c.log(ctx, SubsystemAuth, LevelError, "generate qrcode failed", KV(FieldError, err))
 		os.Exit(-1)
 	}
 
 	qrCode, err := qrcode.New(generateResp.Url, qrcode.Medium)
 	if err != nil {
-		c.logger.Errorf("new qrcode failed: %v", err)
+		c.log(ctx, SubsystemAuth, LevelError, "new qrcode failed", KV(FieldError, err))
 		os.Exit(-1)
 	}
 
 	if err := c.showQRCodeFunc(qrCode); err != nil {
-		c.logger.Errorf("show qrcode failed: %v", err)
+		c.log(ctx, SubsystemAuth, LevelError, "show qrcode failed", KV(FieldError, err))
 		os.Exit(-1)
 	}
 
 	for {
 		resp, cookies, err := c.qrcodePoll(generateResp.QrcodeKey)
 		if err != nil {
-			c.logger.Errorf("poll qrcode failed: %v", err)
+			c.log(ctx, SubsystemAuth, LevelError, "poll qrcode failed", KV(FieldError, err))
 			os.Exit(-1)
 		}
 
@@ -164,14 +289,14 @@ func (c *Client) LoginWithQrCode() {
 			})
 			user, err := c.GetMyAccount()
 			if err != nil {
-				c.logger.Errorf("login failed: %v", err)
+				c.log(ctx, SubsystemAuth, LevelError, "login failed", KV(FieldError, err))
 				os.Exit(-1)
 			}
-			c.mid = user.Mid
-			c.logger.Infof("login success!!!")
+			c.setMid(user.Mid)
+			c.log(ctx, SubsystemAuth, LevelInfo, "login success!!!")
 			return
 		case 86038:
-			c.logger.Errorf("qrcode expired")
+			c.log(ctx, SubsystemAuth, LevelError, "qrcode expired")
 			os.Exit(-1)
 		}
 		time.Sleep(1 * time.Second)
@@ -186,121 +311,57 @@ func (c *Client) Logout() (string, error) {
 	}
 
 	if c.authStorage != nil {
-		if err := c.authStorage.LogoutAuthInfo(c.authInfo); err != nil {
-			c.logger.Errorf("call LogoutAuthInfo failed: %v", err)
+		if err := c.authStorage.LogoutAuthInfo(c.getAuthInfo()); err != nil {
+			c.log(context.Background(), SubsystemAuth, LevelError, "call LogoutAuthInfo failed", KV(FieldError, err))
 		}
 	}
 
-	c.authInfo = nil
+	c.setAuthInfo(nil)
 
 	return resp.RedirectUrl, nil
 }
 
 // UploadVideoFromDisk 从本地磁盘上传视频 videoPath 视频路径
 func (c *Client) UploadVideoFromDisk(videoPath string) (*SubmitVideo, error) {
-	fileInfo, err := os.Stat(videoPath)
+	file, err := os.Open(videoPath)
 	if err != nil {
 		return nil, err
 	}
+	defer file.Close()
 
-	content, err := os.ReadFile(videoPath)
+	fileInfo, err := file.Stat()
 	if err != nil {
 		return nil, err
 	}
 
-	return c.UploadVideo(fileInfo.Name(), content)
+	return c.UploadVideoReaderAt(context.Background(), fileInfo.Name(), file, fileInfo.Size())
 }
 
-// UploadVideoFromReader ...
+// UploadVideoFromReader 大小未知，会先写入临时文件再上传
 func (c *Client) UploadVideoFromReader(filename string, reader io.Reader) (*SubmitVideo, error) {
-	content, err := io.ReadAll(reader)
-	if err != nil {
-		return nil, err
-	}
-
-	return c.UploadVideo(filename, content)
+	return c.UploadVideoStream(context.Background(), filename, reader, -1)
 }
 
-// UploadVideoFromHTTP 从http链接上传文件
+// UploadVideoFromHTTP 从http链接上传文件，响应包含 Content-Length 时边下载边上传，否则见 UploadVideoStream
 func (c *Client) UploadVideoFromHTTP(filename string, url string) (*SubmitVideo, error) {
-	c.logger.Infof("start download file: %v, from: %v", filename, url)
+	c.log(context.Background(), SubsystemUpload, LevelInfo, "start download file", KV("filename", filename), KV("url", url))
 	resp, err := http.Get(url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	return c.UploadVideoFromReader(filename, resp.Body)
+	return c.UploadVideoStream(context.Background(), filename, resp.Body, resp.ContentLength)
 }
 
 // UploadVideo 视频上传，filename 文件名 content 视频内容
 func (c *Client) UploadVideo(filename string, content []byte) (*SubmitVideo, error) {
-	filesize := int64(len(content))
-	// 调接口上传
-	// 1. 预上传
-	preResp, err := c.preUpload(filename, filesize)
-	if err != nil {
-		return nil, err
-	}
-	if preResp.OK != 1 {
-		return nil, fmt.Errorf("[preUpload] upload failed code: %v", preResp.OK)
-	}
-
-	// 2. 获取 upload_id
-	uploadIDResp, err := c.getUploadID(preResp.Uri(), preResp.Auth, preResp.BizID, filesize)
-	if err != nil {
-		return nil, err
-	}
-	if uploadIDResp.OK != 1 {
-		return nil, fmt.Errorf("[getUploadID] upload failed code: %v", uploadIDResp.OK)
-	}
-
-	// 3. 分片上传
-	// 分区
-	parts := utils.SplitBytes(content, 10*utils.MB)
-
-	c.logger.Infof("start upload file: %v, parts: %v, size: %.2fMB", filename, len(parts), float64(len(content))/float64(utils.MB))
-
-	errChan := make(chan error, len(parts))
-	wg := sync.WaitGroup{}
-
-	wg.Add(len(parts))
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
-	for i, part := range parts {
-		go func(part []byte, number int) {
-			defer wg.Done()
-			defer c.logger.Infof("part: %v finished", number)
-			errChan <- c.uploadFileClip(preResp.Uri(), preResp.Auth, uploadIDResp.UploadID, number, len(parts), len(part), (number-1)*10*utils.MB, (number-1)*10*utils.MB+len(part), filesize, part)
-		}(part, i+1)
-	}
-
-	for err := range errChan {
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	// 视频上传完成
-	checkResp, err := c.uploadCheck(preResp.Uri(), preResp.Auth, filename, uploadIDResp.UploadID, preResp.BizID)
-	if err != nil {
-		return nil, err
-	}
-
-	if checkResp.OK != 1 {
-		return nil, fmt.Errorf("[uploadCheck] upload failed code: %v", checkResp.OK)
-	}
-
-	c.logger.Infof("video upload finished success，cid: %v", preResp.BizID)
+	return c.UploadVideoContext(context.Background(), filename, content)
+}
 
-	return &SubmitVideo{
-		Filename: preResp.Filename(),
-		Title:    strings.Split(filename, ".")[0],
-		Desc:     "",
-		CID:      preResp.BizID,
-	}, nil
+// UploadVideoContext 同 UploadVideo，ctx 用于链路追踪
+func (c *Client) UploadVideoContext(ctx context.Context, filename string, content []byte, opts ...UploadOption) (*SubmitVideo, error) {
+	return c.UploadVideoReaderAt(ctx, filename, bytes.NewReader(content), int64(len(content)), opts...)
 }
 
 // UploadCoverFromDisk 从本地磁盘上传封面 imagePath 图片路径
@@ -325,7 +386,7 @@ func (c *Client) UploadCoverFromReader(reader io.Reader) (*UploadCoverResponse,
 
 // UploadCoverFromHTTP 从http链接上传封面
 func (c *Client) UploadCoverFromHTTP(url string) (*UploadCoverResponse, error) {
-	c.logger.Infof("start download cover from: %v", url)
+	c.log(context.Background(), SubsystemUpload, LevelInfo, "start download cover", KV("url", url))
 	resp, err := http.Get(url)
 	if err != nil {
 		return nil, err
@@ -367,47 +428,71 @@ func (c *Client) UnBlock(mid interface{}) error {
 
 // GetFollowers 查询自己的粉丝
 func (c *Client) GetFollowers(ps int, pn int) (*RelationUserResponse, error) {
-	return c.GetUserFollowers(c.mid, ps, pn)
+	return c.GetUserFollowers(c.getMid(), ps, pn)
 }
 
 // GetFollowings 查询自己的关注
 func (c *Client) GetFollowings(orderType string, ps int, pn int) (*RelationUserResponse, error) {
-	return c.GetUserFollowings(c.mid, orderType, ps, pn)
+	return c.GetUserFollowings(c.getMid(), orderType, ps, pn)
 }
 
 // GetFollowingsV2 查询自己的关注
 func (c *Client) GetFollowingsV2(ps int, pn int) (*RelationUserResponse, error) {
-	return c.GetUserFollowingsV2(c.mid, ps, pn)
+	return c.GetUserFollowingsV2(c.getMid(), ps, pn)
 }
 
 // RefreshAuthInfo 刷新token信息
 func (c *Client) RefreshAuthInfo() error {
-	if c.authInfo == nil {
+	return c.RefreshAuthInfoContext(context.Background())
+}
+
+// RefreshAuthInfoContext 同 RefreshAuthInfo，ctx 用于链路追踪
+func (c *Client) RefreshAuthInfoContext(ctx context.Context) (err error) {
+	if c.getAuthInfo() == nil {
 		return nil
 	}
 	c.intervalMutex.Lock()
 	defer c.intervalMutex.Unlock()
+
+	ctx, span := c.startSpan(ctx, "RefreshAuthInfo")
+	ctx = withRequestID(ctx)
+	defer func() { span.End(err) }()
+
 	// 1. 判断是否需要刷新cookie
-	cookieInfo, err := c.getCookieInfo()
+	cookieInfo, err := c.getCookieInfo(ctx)
 	if err != nil {
+		c.metrics.ObserveRefresh(err)
 		return err
 	}
+	span.SetAttributes(Attr(AttrRefresh, cookieInfo.Refresh))
 	if !cookieInfo.Refresh {
 		return nil
 	}
 
-	c.logger.Info("refresh auth info")
+	err = c.refreshAuthInfo(ctx)
+	c.metrics.ObserveRefresh(err)
 
-	oldRefreshToken := c.authInfo.RefreshToken
+	return err
+}
+
+// refreshAuthInfo 刷新cookie并持久化
+func (c *Client) refreshAuthInfo(ctx context.Context) error {
+	c.log(ctx, SubsystemAuth, LevelInfo, "refresh auth info")
+
+	auth := c.getAuthInfo()
+	if auth == nil {
+		return nil
+	}
+	oldRefreshToken := auth.RefreshToken
 
 	// 2. 获取refresh_csrf
-	csrf, err := c.getRefreshCSRF()
+	csrf, err := c.getRefreshCSRF(ctx)
 	if err != nil {
 		return err
 	}
 
 	// 3. 刷新cookie
-	resp, cookies, err := c.refreshCookie(csrf)
+	resp, cookies, err := c.refreshCookie(ctx, csrf, oldRefreshToken)
 	if err != nil {
 		return err
 	}
@@ -419,20 +504,39 @@ func (c *Client) RefreshAuthInfo() error {
 	})
 
 	// 5. 确认更新
-	if err := c.confirmRefresh(oldRefreshToken); err != nil {
+	if err := c.confirmRefresh(ctx, oldRefreshToken); err != nil {
 		return err
 	}
 
 	// 6. 持久化
 	if c.authStorage != nil {
-		if err := c.authStorage.SaveAuthInfo(c.authInfo); err != nil {
-			c.logger.Errorf("SaveAuthInfo failed: %v", err)
+		if err := c.authStorage.SaveAuthInfo(c.getAuthInfo()); err != nil {
+			c.log(ctx, SubsystemAuth, LevelError, "SaveAuthInfo failed", KV(FieldError, err))
 		}
 	}
 
 	return nil
 }
 
+// SubmitVideo 视频投稿
+func (c *Client) SubmitVideo(req *SubmitRequest) (*SubmitResponse, error) {
+	return c.SubmitVideoContext(context.Background(), req)
+}
+
+// SubmitVideoContext 同 SubmitVideo，ctx 用于链路追踪
+func (c *Client) SubmitVideoContext(ctx context.Context, req *SubmitRequest) (resp *SubmitResponse, err error) {
+	ctx, span := c.startSpan(ctx, "SubmitVideo", Attr(AttrVideos, len(req.Videos)))
+	defer func() { span.End(err) }()
+
+	resp, err = c.submitVideo(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(Attr(AttrAid, resp.Aid), Attr(AttrBvid, resp.Bvid))
+
+	return resp, nil
+}
+
 // LikeVideo 点赞视频
 func (c *Client) LikeVideo(id string) error {
 	return c.likeVideo(id, 1)
@@ -445,15 +549,45 @@ func (c *Client) UnLikeVideo(id string) error {
 
 /* ===================== helper ===================== */
 
+// getHttpClient auth 为true时通过 cookie jar 携带认证cookie，只会发送到cookie所属的域名。
+// 中间件从外到内依次为：cookie、WithMiddleware、header、cache、wbi、retry、rateLimit、
+// 上传限速、上传进度、tracing、logging、metrics、proxy、HAR、recorder，
+// 因此缓存只保存重试后的结果，重试的每次请求都会被限流、统计和记录
 func (c *Client) getHttpClient(auth bool) *net.HttpClient {
-	client := c.httpClient.Clone()
+	base := c.httpClient
+	if auth {
+		base = c.authHttpClient
+	}
+	client := base.Clone().Use(c.headerMiddleware())
+
+	if c.cache != nil {
+		client = client.Use(c.cacheMiddleware())
+	}
+
+	client = client.Use(c.wbiMiddleware())
+
+	if c.retryPolicy.MaxRetries > 0 {
+		client = client.Use(c.retryMiddleware())
+	}
+
+	if c.rateLimiter != nil {
+		client = client.Use(c.rateLimitMiddleware())
+	}
+
+	client = client.Use(c.uploadBandwidthMiddleware(), c.uploadProgressMiddleware())
+
+	client = client.Use(c.tracingMiddleware(), c.loggingMiddleware(), metricsMiddleware(c.metrics))
+
+	if c.proxyPool != nil {
+		client = client.Use(c.proxyMiddleware())
+	}
 
-	if c.debug.debug {
-		client = client.Debug(c.debug.output)
+	if c.harWriter != nil {
+		client = client.Use(c.harWriter.Middleware())
 	}
 
-	if auth && c.authInfo != nil {
-		client = client.SetCookies(c.authInfo.Cookies)
+	if c.recorder != nil {
+		client = client.Use(c.recorder.middleware())
 	}
 
 	return client
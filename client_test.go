@@ -0,0 +1,197 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rewriteTransport 将所有请求转发到测试服务器，保留原始的 path 和查询参数
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	request = request.Clone(request.Context())
+	request.Header.Set("X-Original-Host", request.URL.Host)
+	request.URL.Scheme = t.target.Scheme
+	request.URL.Host = t.target.Host
+
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+// newTestClient 创建一个请求全部发往 handler 的客户端
+func newTestClient(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	target, _ := url.Parse(server.URL)
+	opts = append([]Option{
+		WithHttpClient(&http.Client{Transport: rewriteTransport{target: target}}),
+		WithRefreshInterval(0),
+	}, opts...)
+
+	return NewClient(opts...)
+}
+
+// testAPIHandler 模拟部分接口
+func testAPIHandler(calls *int) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/x/web-interface/nav", func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		_, _ = w.Write([]byte(`{"code":0,"data":{"mid":1,"wbi_img":{` +
+			`"img_url":"https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png",` +
+			`"sub_url":"https://i0.hdslb.com/bfs/wbi/4932caff0ff746eab6f01bf08b70ac45.png"}}}`))
+	})
+	mux.HandleFunc("/x/space/wbi/acc/info", func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		if r.URL.Query().Get("w_rid") == "" {
+			_, _ = w.Write([]byte(`{"code":-352,"message":"风控校验失败"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0,"data":{"mid":` + r.URL.Query().Get("mid") + `,"name":"test"}}`))
+	})
+	mux.HandleFunc("/x/relation/stat", func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		_, _ = w.Write([]byte(`{"code":0,"data":{"mid":` + r.URL.Query().Get("vmid") + `,"follower":10}}`))
+	})
+	mux.HandleFunc("/x/relation/modify", func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		_, _ = w.Write([]byte(`{"code":0}`))
+	})
+
+	return mux
+}
+
+// statusMetrics 记录每个请求的状态码
+type statusMetrics struct {
+	nopMetrics
+	mu       sync.Mutex
+	statuses []int
+}
+
+func (m *statusMetrics) ObserveRequest(_ string, _ string, status int, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.statuses = append(m.statuses, status)
+}
+
+func TestMiddlewareChainOrder(t *testing.T) {
+	var calls int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0,"data":{}}`))
+	})
+
+	var cookies []string
+	user := func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			cookies = append(cookies, request.Header.Get("Cookie"))
+			return next(request)
+		}
+	}
+
+	metrics := &statusMetrics{}
+	har := &bytes.Buffer{}
+	client := newTestClient(t, handler,
+		WithMiddleware(user),
+		WithCache(NewLRUCache(10), map[string]time.Duration{"api.bilibili.com/x/test": time.Minute}),
+		WithRetry(RetryPolicy{MaxRetries: 1, Backoff: time.Millisecond}),
+		WithMetrics(metrics),
+		WithDebug(true, har),
+	)
+	client.setAuthInfo(&AuthInfo{Cookies: []*http.Cookie{{Name: "SESSDATA", Value: "sess"}}})
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.getHttpClient(true).Get("https://api.bilibili.com/x/test").End(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// cookie 在用户中间件之前添加，用户中间件在缓存和重试之外
+	if !reflect.DeepEqual(cookies, []string{"SESSDATA=sess", "SESSDATA=sess"}) {
+		t.Errorf("user middleware saw cookies %q", cookies)
+	}
+	// 缓存在重试之外，只保存重试成功后的响应
+	if calls != 2 {
+		t.Errorf("server calls = %d, want 2", calls)
+	}
+	// 指标和 HAR 在重试之内，记录每次请求，缓存命中的请求不会记录
+	if !reflect.DeepEqual(metrics.statuses, []int{http.StatusBadGateway, http.StatusOK}) {
+		t.Errorf("metrics statuses = %v", metrics.statuses)
+	}
+	entries, err := LoadHar(bytes.NewReader(har.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries.Log.Entries) != 2 {
+		t.Fatalf("har entries = %d, want 2", len(entries.Log.Entries))
+	}
+	for _, entry := range entries.Log.Entries {
+		if len(entry.Request.Cookies) != 1 || entry.Request.Cookies[0].Value != "[REDACTED]" {
+			t.Errorf("har cookies = %+v, want redacted SESSDATA", entry.Request.Cookies)
+		}
+	}
+}
+
+type countingBody struct {
+	io.Reader
+	reads int
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	b.reads++
+	return b.Reader.Read(p)
+}
+
+func (b *countingBody) Close() error { return nil }
+
+func TestPeekCodeOnce(t *testing.T) {
+	body := &countingBody{Reader: strings.NewReader(`{"code":-412}`)}
+	resp := &http.Response{Body: body}
+
+	reads := 0
+	for i := 0; i < 3; i++ {
+		code, err := peekCode(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code == nil || *code != -412 {
+			t.Fatalf("code = %v, want -412", code)
+		}
+		if i == 0 {
+			reads = body.reads
+		}
+	}
+	if _, ok := resp.Body.(*peekedBody); !ok {
+		t.Fatalf("body = %T, want *peekedBody", resp.Body)
+	}
+
+	bts, _ := io.ReadAll(resp.Body)
+	if string(bts) != `{"code":-412}` || body.reads != reads {
+		t.Errorf("body = %q, reads = %d, want original body read once", bts, body.reads)
+	}
+}
+
+func TestNilMetricsAndTracer(t *testing.T) {
+	var calls int
+	client := newTestClient(t, testAPIHandler(&calls), WithMetrics(nil), WithTracer(nil))
+
+	if _, err := client.GetRelationStat(1); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,401 @@
+package bilibili_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultEnvPrefix LoadConfig 使用的环境变量前缀
+const DefaultEnvPrefix = "BILIBILI_"
+
+// Duration 配置文件中的时间间隔，使用 time.ParseDuration 的格式，比如 "30s"、"12h"
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+
+	return nil
+}
+
+// Config 客户端配置，可以从 yaml、toml、json 文件以及环境变量加载，
+// 环境变量名为前缀加上大写的字段路径，比如 BILIBILI_PROXY_URLS，切片使用逗号分隔
+type Config struct {
+	// UserAgent 默认的 User-Agent
+	UserAgent string `json:"user_agent" yaml:"user_agent" toml:"user_agent"`
+
+	// UserAgents User-Agent 池，见 WithUserAgentPool
+	UserAgents []string `json:"user_agents" yaml:"user_agents" toml:"user_agents"`
+
+	// Timeout 请求超时时间，为0则不超时
+	Timeout Duration `json:"timeout" yaml:"timeout" toml:"timeout"`
+
+	Proxy ProxyConfig `json:"proxy" yaml:"proxy" toml:"proxy"`
+
+	Debug DebugConfig `json:"debug" yaml:"debug" toml:"debug"`
+
+	Auth AuthConfig `json:"auth" yaml:"auth" toml:"auth"`
+
+	RateLimit RateLimitConfig `json:"rate_limit" yaml:"rate_limit" toml:"rate_limit"`
+
+	Retry RetryConfig `json:"retry" yaml:"retry" toml:"retry"`
+
+	Log LogConfig `json:"log" yaml:"log" toml:"log"`
+
+	Upload UploadConfig `json:"upload" yaml:"upload" toml:"upload"`
+}
+
+// ProxyConfig 代理配置，见 NewProxyPool
+type ProxyConfig struct {
+	// URLs 代理地址，为空则不使用代理
+	URLs []string `json:"urls" yaml:"urls" toml:"urls"`
+
+	// Strategy 选择策略，可选 round_robin（默认）、sticky、least_failures
+	Strategy string `json:"strategy" yaml:"strategy" toml:"strategy"`
+
+	// MaxFailures 连续失败多少次后剔除
+	MaxFailures int `json:"max_failures" yaml:"max_failures" toml:"max_failures"`
+
+	// HealthCheckInterval 健康检查间隔
+	HealthCheckInterval Duration `json:"health_check_interval" yaml:"health_check_interval" toml:"health_check_interval"`
+}
+
+// DebugConfig 调试配置，见 WithDebug
+type DebugConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled" toml:"enabled"`
+
+	// Output HAR 输出位置，可选 stdout（默认）、stderr 或文件路径，文件会被覆盖，需要调用 Client.Close 补全结尾并关闭
+	Output string `json:"output" yaml:"output" toml:"output"`
+
+	// MaxBodySize 见 HarOptions
+	MaxBodySize int `json:"max_body_size" yaml:"max_body_size" toml:"max_body_size"`
+}
+
+// AuthConfig 登陆信息存储配置
+type AuthConfig struct {
+	// Storage 存储后端，见 AuthStorageBackends，为空则不保存登陆信息
+	Storage string `json:"storage" yaml:"storage" toml:"storage"`
+
+	// Path 传给存储后端的路径，比如 file 后端的文件路径
+	Path string `json:"path" yaml:"path" toml:"path"`
+
+	// RefreshInterval 检查并刷新cookie的间隔，为0则使用默认值
+	RefreshInterval Duration `json:"refresh_interval" yaml:"refresh_interval" toml:"refresh_interval"`
+}
+
+// RateLimitConfig 限流配置，见 WithRateLimit
+type RateLimitConfig struct {
+	// RPS 每秒请求数，为0则不限流
+	RPS float64 `json:"rps" yaml:"rps" toml:"rps"`
+
+	Burst int `json:"burst" yaml:"burst" toml:"burst"`
+}
+
+// RetryConfig 重试配置，见 WithRetry
+type RetryConfig struct {
+	// MaxRetries 最大重试次数，为0则不重试
+	MaxRetries int `json:"max_retries" yaml:"max_retries" toml:"max_retries"`
+
+	Backoff Duration `json:"backoff" yaml:"backoff" toml:"backoff"`
+
+	MaxBackoff Duration `json:"max_backoff" yaml:"max_backoff" toml:"max_backoff"`
+}
+
+// LogConfig 日志等级配置，等级可选 debug、info、warn、error
+type LogConfig struct {
+	// Level 所有子系统的日志等级
+	Level string `json:"level" yaml:"level" toml:"level"`
+
+	// Subsystems 单独设置子系统的日志等级，比如 {"upload": "debug"}，不支持环境变量覆盖
+	Subsystems map[string]string `json:"subsystems" yaml:"subsystems" toml:"subsystems"`
+}
+
+// UploadConfig 视频上传配置
+type UploadConfig struct {
+	// SessionDir 上传会话保存目录，为空则不保存，见 WithUploadSessionStore
+	SessionDir string `json:"session_dir" yaml:"session_dir" toml:"session_dir"`
+
+	// Bandwidth 所有上传的总带宽限制，字节/秒，见 WithUploadBandwidth
+	Bandwidth int `json:"bandwidth" yaml:"bandwidth" toml:"bandwidth"`
+
+	// Lines 上传线路名称，按顺序尝试，见 WithDefaultUploadLines
+	Lines []string `json:"lines" yaml:"lines" toml:"lines"`
+
+	// Probe 上传前是否对线路测速，见 WithDefaultLineProbe
+	Probe bool `json:"probe" yaml:"probe" toml:"probe"`
+}
+
+// AuthStorageBackends 配置文件中可用的登陆信息存储后端，可以注册自定义后端
+var AuthStorageBackends = map[string]func(path string) (AuthStorage, error){
+	"file": func(path string) (AuthStorage, error) {
+		if path == "" {
+			return nil, fmt.Errorf("file auth storage requires path")
+		}
+
+		return NewFileAuthStorage(path), nil
+	},
+}
+
+// LoadConfig 从文件加载配置，根据扩展名支持 .yaml、.yml、.toml 和 .json，
+// 之后使用前缀为 DefaultEnvPrefix 的环境变量覆盖
+func LoadConfig(path string) (*Config, error) {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(bts, cfg)
+	case ".toml":
+		err = toml.Unmarshal(bts, cfg)
+	case ".json":
+		err = json.Unmarshal(bts, cfg)
+	default:
+		return nil, fmt.Errorf("unsupported config file type %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	if err = cfg.ApplyEnv(DefaultEnvPrefix); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// ApplyEnv 使用环境变量覆盖配置，变量名为 prefix 加上大写的字段路径，比如 BILIBILI_LOG_LEVEL
+func (c *Config) ApplyEnv(prefix string) error {
+	return applyEnv(reflect.ValueOf(c).Elem(), prefix)
+}
+
+var textUnmarshalerType = reflect.TypeOf((*interface{ UnmarshalText([]byte) error })(nil)).Elem()
+
+func applyEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		name := prefix + strings.ToUpper(strings.Split(field.Tag.Get("json"), ",")[0])
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := applyEnv(value, name+"_"); err != nil {
+				return err
+			}
+			continue
+		}
+
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch {
+		case value.Addr().Type().Implements(textUnmarshalerType):
+			err = value.Addr().Interface().(interface{ UnmarshalText([]byte) error }).UnmarshalText([]byte(env))
+		case field.Type.Kind() == reflect.String:
+			value.SetString(env)
+		case field.Type.Kind() == reflect.Bool:
+			var b bool
+			b, err = strconv.ParseBool(env)
+			value.SetBool(b)
+		case field.Type.Kind() == reflect.Int:
+			var n int64
+			n, err = strconv.ParseInt(env, 10, 64)
+			value.SetInt(n)
+		case field.Type.Kind() == reflect.Float64:
+			var f float64
+			f, err = strconv.ParseFloat(env, 64)
+			value.SetFloat(f)
+		case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.String:
+			var values []string
+			for _, each := range strings.Split(env, ",") {
+				if each = strings.TrimSpace(each); each != "" {
+					values = append(values, each)
+				}
+			}
+			value.Set(reflect.ValueOf(values))
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("invalid env %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Options 将配置转换为 Option
+func (c *Config) Options() ([]Option, error) {
+	var opts []Option
+
+	if c.UserAgent != "" {
+		opts = append(opts, WithUserAgent(c.UserAgent))
+	}
+	if len(c.UserAgents) > 0 {
+		opts = append(opts, WithUserAgentPool(c.UserAgents...))
+	}
+	if c.Timeout > 0 {
+		opts = append(opts, WithHttpClient(&http.Client{Timeout: time.Duration(c.Timeout)}))
+	}
+
+	if c.Auth.Storage != "" {
+		backend, ok := AuthStorageBackends[c.Auth.Storage]
+		if !ok {
+			return nil, fmt.Errorf("unknown auth storage %q", c.Auth.Storage)
+		}
+		storage, err := backend(c.Auth.Path)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithAuthStorage(storage))
+	}
+	if c.Auth.RefreshInterval > 0 {
+		opts = append(opts, WithRefreshInterval(time.Duration(c.Auth.RefreshInterval)))
+	}
+
+	if c.Upload.SessionDir != "" {
+		opts = append(opts, WithUploadSessionStore(NewFileUploadSessionStore(c.Upload.SessionDir)))
+	}
+	if c.Upload.Bandwidth > 0 {
+		opts = append(opts, WithUploadBandwidth(int64(c.Upload.Bandwidth)))
+	}
+	if len(c.Upload.Lines) > 0 {
+		lines := make([]UploadLine, 0, len(c.Upload.Lines))
+		for _, name := range c.Upload.Lines {
+			line, err := UploadLineByName(name)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, line)
+		}
+		opts = append(opts, WithDefaultUploadLines(lines...))
+	}
+	if c.Upload.Probe {
+		opts = append(opts, WithDefaultLineProbe(true))
+	}
+
+	if c.RateLimit.RPS > 0 {
+		burst := c.RateLimit.Burst
+		if burst <= 0 {
+			burst = 1
+		}
+		opts = append(opts, WithRateLimit(c.RateLimit.RPS, burst))
+	}
+	if c.Retry.MaxRetries > 0 {
+		opts = append(opts, WithRetry(RetryPolicy{
+			MaxRetries: c.Retry.MaxRetries,
+			Backoff:    time.Duration(c.Retry.Backoff),
+			MaxBackoff: time.Duration(c.Retry.MaxBackoff),
+		}))
+	}
+
+	if c.Log.Level != "" {
+		level, err := parseLogLevel(c.Log.Level)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithLogLevel(level))
+	}
+	for subsystem, each := range c.Log.Subsystems {
+		level, err := parseLogLevel(each)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithLogLevel(level, Subsystem(subsystem)))
+	}
+
+	strategy, err := parseProxyStrategy(c.Proxy.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	// 以下会启动健康检查或打开文件，放在最后避免配置错误时泄漏；
+	// 代理地址可能无效，先创建代理池，打开文件失败时再关闭代理池
+	var pool *ProxyPool
+	if len(c.Proxy.URLs) > 0 {
+		pool, err = NewProxyPool(c.Proxy.URLs, ProxyPoolOptions{
+			Strategy:            strategy,
+			MaxFailures:         c.Proxy.MaxFailures,
+			HealthCheckInterval: time.Duration(c.Proxy.HealthCheckInterval),
+		})
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithProxyPool(pool))
+	}
+
+	if c.Debug.Enabled {
+		switch c.Debug.Output {
+		case "", "stdout":
+			opts = append(opts, WithDebug(true, os.Stdout))
+		case "stderr":
+			opts = append(opts, WithDebug(true, os.Stderr))
+		default:
+			// 每次覆盖写入，追加会导致文件中有多个 HAR 文档而无法加载
+			file, err := os.OpenFile(c.Debug.Output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				if pool != nil {
+					pool.Close()
+				}
+				return nil, err
+			}
+			opts = append(opts, debug{&debugInfo{debug: true, output: file, closer: file}})
+		}
+		opts = append(opts, WithDebugOptions(HarOptions{MaxBodySize: c.Debug.MaxBodySize}))
+	}
+
+	return opts, nil
+}
+
+// NewClientFromConfig 根据配置创建客户端，opts 在配置之后应用，可以用于设置日志、指标等无法通过配置文件设置的选项
+func NewClientFromConfig(cfg *Config, opts ...Option) (*Client, error) {
+	cfgOpts, err := cfg.Options()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(append(cfgOpts, opts...)...), nil
+}
+
+func parseLogLevel(s string) (LogLevel, error) {
+	for _, level := range []LogLevel{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		if strings.EqualFold(s, level.String()) {
+			return level, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+func parseProxyStrategy(s string) (ProxyStrategy, error) {
+	switch strings.ToLower(s) {
+	case "", "round_robin":
+		return ProxyRoundRobin, nil
+	case "sticky":
+		return ProxySticky, nil
+	case "least_failures":
+		return ProxyLeastFailures, nil
+	}
+
+	return 0, fmt.Errorf("unknown proxy strategy %q", s)
+}
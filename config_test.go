@@ -0,0 +1,227 @@
+package bilibili_go
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	want := Config{
+		UserAgent: "test-agent",
+		Timeout:   Duration(30 * time.Second),
+		Proxy:     ProxyConfig{URLs: []string{"http://127.0.0.1:8080"}, Strategy: "sticky"},
+		Debug:     DebugConfig{Enabled: true, Output: "stderr"},
+		Auth:      AuthConfig{Storage: "file", Path: "auth.json", RefreshInterval: Duration(12 * time.Hour)},
+		RateLimit: RateLimitConfig{RPS: 5, Burst: 10},
+		Log:       LogConfig{Level: "info", Subsystems: map[string]string{"upload": "debug"}},
+	}
+
+	files := map[string]string{
+		"config.yaml": `
+user_agent: test-agent
+timeout: 30s
+proxy:
+  urls: [http://127.0.0.1:8080]
+  strategy: sticky
+debug:
+  enabled: true
+  output: stderr
+auth:
+  storage: file
+  path: auth.json
+  refresh_interval: 12h
+rate_limit:
+  rps: 5
+  burst: 10
+log:
+  level: info
+  subsystems:
+    upload: debug
+`,
+		"config.toml": `
+user_agent = "test-agent"
+timeout = "30s"
+
+[proxy]
+urls = ["http://127.0.0.1:8080"]
+strategy = "sticky"
+
+[debug]
+enabled = true
+output = "stderr"
+
+[auth]
+storage = "file"
+path = "auth.json"
+refresh_interval = "12h"
+
+[rate_limit]
+rps = 5.0
+burst = 10
+
+[log]
+level = "info"
+subsystems = { upload = "debug" }
+`,
+		"config.json": `{
+  "user_agent": "test-agent",
+  "timeout": "30s",
+  "proxy": {"urls": ["http://127.0.0.1:8080"], "strategy": "sticky"},
+  "debug": {"enabled": true, "output": "stderr"},
+  "auth": {"storage": "file", "path": "auth.json", "refresh_interval": "12h"},
+  "rate_limit": {"rps": 5, "burst": 10},
+  "log": {"level": "info", "subsystems": {"upload": "debug"}}
+}`,
+	}
+
+	dir := t.TempDir()
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*cfg, want) {
+				t.Errorf("LoadConfig() = %+v, want %+v", *cfg, want)
+			}
+		})
+	}
+}
+
+func TestConfigApplyEnv(t *testing.T) {
+	t.Setenv("BILIBILI_USER_AGENTS", "a, b")
+	t.Setenv("BILIBILI_PROXY_URLS", "http://127.0.0.1:1080")
+	t.Setenv("BILIBILI_AUTH_PATH", "/data/auth.json")
+	t.Setenv("BILIBILI_AUTH_REFRESH_INTERVAL", "1h")
+	t.Setenv("BILIBILI_RATE_LIMIT_RPS", "2.5")
+	t.Setenv("BILIBILI_DEBUG_ENABLED", "true")
+	t.Setenv("BILIBILI_LOG_LEVEL", "warn")
+	t.Setenv("BILIBILI_UPLOAD_SESSION_DIR", "/data/upload")
+	t.Setenv("BILIBILI_UPLOAD_BANDWIDTH", "1048576")
+
+	cfg := &Config{Auth: AuthConfig{Storage: "file", Path: "auth.json"}, Log: LogConfig{Level: "info"}}
+	if err := cfg.ApplyEnv(DefaultEnvPrefix); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(cfg.UserAgents, []string{"a", "b"}) {
+		t.Errorf("user agents = %v", cfg.UserAgents)
+	}
+	if !reflect.DeepEqual(cfg.Proxy.URLs, []string{"http://127.0.0.1:1080"}) {
+		t.Errorf("proxy urls = %v", cfg.Proxy.URLs)
+	}
+	if cfg.Auth.Storage != "file" || cfg.Auth.Path != "/data/auth.json" || cfg.Auth.RefreshInterval != Duration(time.Hour) {
+		t.Errorf("auth = %+v", cfg.Auth)
+	}
+	if cfg.RateLimit.RPS != 2.5 || !cfg.Debug.Enabled || cfg.Log.Level != "warn" || cfg.Upload.SessionDir != "/data/upload" || cfg.Upload.Bandwidth != 1<<20 {
+		t.Errorf("config = %+v", cfg)
+	}
+
+	t.Setenv("BILIBILI_TIMEOUT", "soon")
+	if err := cfg.ApplyEnv(DefaultEnvPrefix); err == nil {
+		t.Error("ApplyEnv() with invalid duration should return error")
+	}
+}
+
+func TestNewClientFromConfig(t *testing.T) {
+	cfg := &Config{
+		UserAgents: []string{"a", "b"},
+		Proxy:      ProxyConfig{URLs: []string{"http://127.0.0.1:8080"}, Strategy: "least_failures", HealthCheckInterval: -1},
+		Auth:       AuthConfig{Storage: "file", Path: filepath.Join(t.TempDir(), "auth.json")},
+		RateLimit:  RateLimitConfig{RPS: 5, Burst: 10},
+		Retry:      RetryConfig{MaxRetries: 2},
+		Upload:     UploadConfig{Lines: []string{"txa", "alia"}, Probe: true},
+		Log:        LogConfig{Level: "warn", Subsystems: map[string]string{"upload": "debug"}},
+	}
+
+	client, err := NewClientFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.proxyPool == nil || client.proxyPool.opts.Strategy != ProxyLeastFailures {
+		t.Error("proxy pool not configured")
+	}
+	if client.authStorage == nil || client.rateLimiter == nil || client.retryPolicy.MaxRetries != 2 {
+		t.Error("auth storage, rate limit or retry not configured")
+	}
+	if client.logLevel(SubsystemHTTP) != LevelWarn || client.logLevel(SubsystemUpload) != LevelDebug {
+		t.Errorf("log levels = %v", client.logLevels)
+	}
+	if !reflect.DeepEqual(client.defaultUploadLines, []UploadLine{LineTxa, LineAlia}) || !client.probeUploadLines {
+		t.Errorf("upload lines = %v", client.defaultUploadLines)
+	}
+	if client.userAgent != "a" && client.userAgent != "b" {
+		t.Errorf("user agent = %q, want one of the pool", client.userAgent)
+	}
+
+	for _, invalid := range []*Config{
+		{Auth: AuthConfig{Storage: "redis"}},
+		{Log: LogConfig{Level: "verbose"}},
+		{Proxy: ProxyConfig{URLs: []string{"http://127.0.0.1:8080"}, Strategy: "random"}},
+		{Upload: UploadConfig{Lines: []string{"upos"}}},
+	} {
+		if _, err := NewClientFromConfig(invalid); err == nil {
+			t.Errorf("NewClientFromConfig(%+v) should return error", invalid)
+		}
+	}
+}
+
+func TestDebugFileConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debug.har")
+	if err := os.WriteFile(path, []byte("previous run"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	cfg := &Config{Debug: DebugConfig{Enabled: true, Output: path}}
+	client, err := NewClientFromConfig(cfg, WithHttpClient(&http.Client{Transport: rewriteTransport{target: target}}), WithRefreshInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := client.getHttpClient(false).Get("https://api.bilibili.com/x/test").End(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+
+	// 文件被覆盖，Close 后是完整的 HAR 文档
+	har, err := LoadHarFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(har.Log.Entries) != 1 {
+		t.Errorf("entries = %d, want 1", len(har.Log.Entries))
+	}
+}
+
+func TestDebugFileConfigInvalidProxy(t *testing.T) {
+	// 代理地址无效时不会创建调试文件
+	path := filepath.Join(t.TempDir(), "debug.har")
+	cfg := &Config{Debug: DebugConfig{Enabled: true, Output: path}, Proxy: ProxyConfig{URLs: []string{"://invalid"}}}
+	if _, err := cfg.Options(); err == nil {
+		t.Fatal("Options() should fail with an invalid proxy url")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("debug file should not be created, stat error = %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package bilibili_go
+
+import (
+	"context"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 认证 cookie 默认作用域，没有 Domain 的 cookie（比如从旧版存储中加载的）视为该域名下的 cookie
+const defaultCookieDomain = ".bilibili.com"
+
+var bilibiliURL = &url.URL{Scheme: "https", Host: "www.bilibili.com", Path: "/"}
+
+// cookieJar 基于 net/http/cookiejar，按 Domain 和 Path 发送 cookie，避免把 SESSDATA 发送到 upos 等非 bilibili.com 域名，
+// 响应中的 Set-Cookie 会通过 onSetCookies 回调，用于更新并持久化认证信息
+type cookieJar struct {
+	mu           sync.RWMutex
+	jar          *cookiejar.Jar
+	onSetCookies func(u *url.URL, cookies []*http.Cookie)
+}
+
+func newCookieJar(onSetCookies func(u *url.URL, cookies []*http.Cookie)) *cookieJar {
+	jar, _ := cookiejar.New(nil)
+
+	return &cookieJar{jar: jar, onSetCookies: onSetCookies}
+}
+
+func (j *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.RLock()
+	j.jar.SetCookies(u, cookies)
+	j.mu.RUnlock()
+
+	if j.onSetCookies != nil && len(cookies) > 0 {
+		j.onSetCookies(u, cookies)
+	}
+}
+
+func (j *cookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	return j.jar.Cookies(u)
+}
+
+// responseJar 只接收响应中的 Set-Cookie（包括重定向过程中的响应），不向请求添加 cookie，
+// 请求的 cookie 由 cookieMiddleware 添加，这样中间件、HAR 以及 recorder 都能看到并脱敏真实的 Cookie 头
+type responseJar struct {
+	*cookieJar
+}
+
+func (responseJar) Cookies(*url.URL) []*http.Cookie {
+	return nil
+}
+
+// cookieMiddleware 按请求地址从 jar 中添加认证 cookie，位于所有中间件的最外层
+func (c *Client) cookieMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			cookies := c.jar.Cookies(request.URL)
+			if len(cookies) == 0 {
+				return next(request)
+			}
+
+			request = request.Clone(request.Context())
+			for _, cookie := range cookies {
+				request.AddCookie(cookie)
+			}
+
+			return next(request)
+		}
+	}
+}
+
+// reset 清空后加载 cookies，不会触发 onSetCookies
+func (j *cookieJar) reset(cookies []*http.Cookie) {
+	jar, _ := cookiejar.New(nil)
+
+	scoped := make([]*http.Cookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		cookie := *cookie
+		if cookie.Domain == "" {
+			cookie.Domain = defaultCookieDomain
+		}
+		if cookie.Path == "" {
+			cookie.Path = "/"
+		}
+		scoped = append(scoped, &cookie)
+	}
+	jar.SetCookies(bilibiliURL, scoped)
+
+	j.mu.Lock()
+	j.jar = jar
+	j.mu.Unlock()
+}
+
+// isBilibiliHost 是否为 bilibili.com 及其子域名
+func isBilibiliHost(host string) bool {
+	host = strings.ToLower(host)
+
+	return host == "bilibili.com" || strings.HasSuffix(host, ".bilibili.com")
+}
+
+// mergeCookies 将 bilibili.com 响应中的 Set-Cookie 合并到认证信息并持久化，
+// 同名 cookie 以新的为准，已过期的会被删除
+func (c *Client) mergeCookies(u *url.URL, cookies []*http.Cookie) {
+	if !isBilibiliHost(u.Hostname()) {
+		return
+	}
+
+	c.authMutex.Lock()
+	if c.authInfo == nil {
+		c.authMutex.Unlock()
+		return
+	}
+
+	merged := make([]*http.Cookie, 0, len(c.authInfo.Cookies)+len(cookies))
+	merged = append(merged, c.authInfo.Cookies...)
+	changed := false
+	for _, cookie := range cookies {
+		expired := cookie.MaxAge < 0 || (!cookie.Expires.IsZero() && cookie.Expires.Before(time.Now()))
+
+		idx := -1
+		for i, each := range merged {
+			if each.Name == cookie.Name {
+				idx = i
+				break
+			}
+		}
+
+		switch {
+		case expired && idx >= 0:
+			merged = append(merged[:idx], merged[idx+1:]...)
+			changed = true
+		case expired:
+		case idx < 0:
+			merged = append(merged, cookie)
+			changed = true
+		case merged[idx].Value != cookie.Value:
+			merged[idx] = cookie
+			changed = true
+		}
+	}
+	if !changed {
+		c.authMutex.Unlock()
+		return
+	}
+
+	c.authInfo = &AuthInfo{Cookies: merged, RefreshToken: c.authInfo.RefreshToken}
+	c.updateCSRF()
+	authInfo := c.authInfo
+	c.authMutex.Unlock()
+
+	c.log(context.Background(), SubsystemAuth, LevelDebug, "cookies updated", KV(FieldEndpoint, Endpoint(u)))
+
+	if c.authStorage != nil {
+		if err := c.authStorage.SaveAuthInfo(authInfo); err != nil {
+			c.log(context.Background(), SubsystemAuth, LevelError, "SaveAuthInfo failed", KV(FieldError, err))
+		}
+	}
+}
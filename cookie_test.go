@@ -0,0 +1,110 @@
+package bilibili_go
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+type memoryAuthStorage struct {
+	saved *AuthInfo
+}
+
+func (m *memoryAuthStorage) LoadAuthInfo() (*AuthInfo, error) { return m.saved, nil }
+
+func (m *memoryAuthStorage) SaveAuthInfo(info *AuthInfo) error {
+	m.saved = info
+	return nil
+}
+
+func (m *memoryAuthStorage) LogoutAuthInfo(*AuthInfo) error { return nil }
+
+func TestCookieJar(t *testing.T) {
+	received := make(map[string]string)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received[r.Header.Get("X-Original-Host")] = r.Header.Get("Cookie")
+		if r.URL.Path == "/set" {
+			http.SetCookie(w, &http.Cookie{Name: "bili_jct", Value: "new-csrf", Domain: ".bilibili.com", Path: "/"})
+		}
+		_, _ = w.Write([]byte(`{"code":0}`))
+	})
+
+	storage := &memoryAuthStorage{}
+	client := newTestClient(t, handler, WithAuthStorage(storage))
+	client.setAuthInfo(&AuthInfo{Cookies: []*http.Cookie{
+		{Name: "SESSDATA", Value: "sess"},
+		{Name: "bili_jct", Value: "old-csrf"},
+	}})
+
+	for _, uri := range []string{
+		"https://api.bilibili.com/x/web-interface/nav",
+		"https://upos-cs-upcdnbda2.bilivideo.com/video.mp4",
+	} {
+		if _, _, err := client.getHttpClient(true).Get(uri).End(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := received["api.bilibili.com"]; got != "SESSDATA=sess; bili_jct=old-csrf" {
+		t.Errorf("api.bilibili.com cookie = %q", got)
+	}
+	if got := received["upos-cs-upcdnbda2.bilivideo.com"]; got != "" {
+		t.Errorf("upos cookie = %q, want none", got)
+	}
+
+	if _, _, err := client.getHttpClient(true).Get("https://passport.bilibili.com/set").End(); err != nil {
+		t.Fatal(err)
+	}
+	if csrf := client.getCSRF(); csrf != "new-csrf" {
+		t.Errorf("csrf = %q, want new-csrf", csrf)
+	}
+	if storage.saved == nil || len(storage.saved.Cookies) != 2 {
+		t.Fatalf("saved auth info = %+v", storage.saved)
+	}
+}
+
+func TestCookieJarConcurrent(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if value := r.URL.Query().Get("v"); value != "" {
+			http.SetCookie(w, &http.Cookie{Name: "bili_jct", Value: value, Domain: ".bilibili.com", Path: "/"})
+		}
+		_, _ = w.Write([]byte(`{"code":0}`))
+	})
+
+	client := newTestClient(t, handler)
+	client.setAuthInfo(&AuthInfo{Cookies: []*http.Cookie{
+		{Name: "SESSDATA", Value: "sess"},
+		{Name: "bili_jct", Value: "csrf-0"},
+	}})
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			uri := fmt.Sprintf("https://passport.bilibili.com/set?v=csrf-%d", i)
+			if _, _, err := client.getHttpClient(true).Get(uri).End(); err != nil {
+				t.Error(err)
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			_, err := client.Call(context.Background(), Request{
+				Method: http.MethodPost,
+				URL:    "https://api.bilibili.com/x/test",
+				Form:   map[string][]string{"k": {"v"}},
+				CSRF:   true,
+			})
+			if err != nil {
+				t.Error(err)
+			}
+			_ = client.getAuthInfo()
+		}()
+	}
+	wg.Wait()
+
+	if csrf := client.getCSRF(); csrf == "csrf-0" {
+		t.Errorf("csrf = %q, want updated by Set-Cookie", csrf)
+	}
+}
@@ -0,0 +1,239 @@
+package bilibili_go
+
+import (
+	"context"
+	"strings"
+)
+
+// defaultCreatorArchivePageSize 遍历稿件时每页的数量
+const defaultCreatorArchivePageSize = 20
+
+// ArchiveListStatus 稿件列表的状态筛选
+type ArchiveListStatus string
+
+const (
+	ArchiveListOpen      ArchiveListStatus = "pubed"     // 已通过
+	ArchiveListReviewing ArchiveListStatus = "is_pubing" // 进行中，包括审核中和转码中
+	ArchiveListRejected  ArchiveListStatus = "not_pubed" // 未通过
+)
+
+// ArchiveState 稿件状态，大于等于0为开放浏览，未列出的状态视为审核中
+type ArchiveState int
+
+const (
+	ArchiveStateOrange          ArchiveState = 1    // 橙色通过
+	ArchiveStateOpen            ArchiveState = 0    // 开放浏览
+	ArchiveStatePending         ArchiveState = -1   // 待审
+	ArchiveStateRejected        ArchiveState = -2   // 被打回
+	ArchiveStatePoliceLocked    ArchiveState = -3   // 网警锁定
+	ArchiveStateLocked          ArchiveState = -4   // 被锁定
+	ArchiveStateAdminLocked     ArchiveState = -5   // 管理员锁定
+	ArchiveStateRepairPending   ArchiveState = -6   // 修复待审
+	ArchiveStateDeferred        ArchiveState = -7   // 暂缓审核
+	ArchiveStateReuploadPending ArchiveState = -8   // 补档待审
+	ArchiveStateWaitTranscode   ArchiveState = -9   // 等待转码
+	ArchiveStateDelayed         ArchiveState = -10  // 延迟审核
+	ArchiveStateSourceRepair    ArchiveState = -11  // 视频源待修
+	ArchiveStateStoreFailed     ArchiveState = -12  // 转储失败
+	ArchiveStateCommentPending  ArchiveState = -13  // 允许评论待审
+	ArchiveStateRecycled        ArchiveState = -14  // 临时回收站
+	ArchiveStateDistributing    ArchiveState = -15  // 分发中
+	ArchiveStateTranscodeFailed ArchiveState = -16  // 转码失败
+	ArchiveStateNotSubmitted    ArchiveState = -20  // 创建未提交
+	ArchiveStateSubmitted       ArchiveState = -30  // 创建已提交
+	ArchiveStateScheduled       ArchiveState = -40  // 定时发布
+	ArchiveStateDeleted         ArchiveState = -100 // 用户删除
+
+	// Deprecated: -30 是创建已提交而不是转码中，使用 ArchiveStateSubmitted 或者 IsTranscoding
+	ArchiveStateTranscode = ArchiveStateSubmitted
+)
+
+// IsOpen 是否开放浏览
+func (s ArchiveState) IsOpen() bool {
+	return s >= 0
+}
+
+// IsLocked 是否被锁定
+func (s ArchiveState) IsLocked() bool {
+	return s == ArchiveStatePoliceLocked || s == ArchiveStateLocked || s == ArchiveStateAdminLocked
+}
+
+// IsTranscoding 是否在转码或者分发中
+func (s ArchiveState) IsTranscoding() bool {
+	switch s {
+	case ArchiveStateWaitTranscode, ArchiveStateSubmitted, ArchiveStateDistributing:
+		return true
+	}
+
+	return false
+}
+
+// IsRejected 是否未通过，包括被打回、视频源待修、转储或转码失败、回收站、锁定和删除，需要处理后重新提交
+func (s ArchiveState) IsRejected() bool {
+	switch s {
+	case ArchiveStateRejected, ArchiveStateSourceRepair, ArchiveStateStoreFailed, ArchiveStateRecycled,
+		ArchiveStateTranscodeFailed, ArchiveStateDeleted:
+		return true
+	}
+
+	return s.IsLocked()
+}
+
+// IsReviewing 是否还在审核或转码等流程中，包括待审、暂缓审核、延迟审核、定时发布以及未列出的状态
+func (s ArchiveState) IsReviewing() bool {
+	return !s.IsOpen() && !s.IsRejected()
+}
+
+// ArchiveReview 稿件的审核状态
+type ArchiveReview struct {
+	Aid          int64
+	Bvid         string
+	Title        string
+	State        ArchiveState
+	StateDesc    string
+	RejectReason string
+
+	// Videos 各分P的状态和退回原因
+	Videos []*CreatorArchiveVideo
+}
+
+// GetCreatorArchives 分页获取自己的稿件，statuses 为空时获取全部
+func (c *Client) GetCreatorArchives(ctx context.Context, pn int, ps int, statuses ...ArchiveListStatus) (*GetCreatorArchivesResponse, error) {
+	status := make([]string, 0, len(statuses))
+	for _, s := range statuses {
+		status = append(status, string(s))
+	}
+
+	return c.getCreatorArchives(ctx, strings.Join(status, ","), pn, ps)
+}
+
+// GetArchiveReview 获取稿件的审核状态和退回原因
+// id 视频ID av号或者bv号
+func (c *Client) GetArchiveReview(ctx context.Context, id string) (*ArchiveReview, error) {
+	view, err := c.getArchiveView(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	review := &ArchiveReview{
+		Aid:          view.Archive.Aid,
+		Bvid:         view.Archive.Bvid,
+		Title:        view.Archive.Title,
+		State:        view.Archive.State,
+		StateDesc:    view.Archive.StateDesc,
+		RejectReason: view.Archive.RejectReason,
+	}
+	for _, video := range view.Videos {
+		review.Videos = append(review.Videos, &CreatorArchiveVideo{
+			CID:          video.CID,
+			Index:        video.Index,
+			Title:        video.Title,
+			Filename:     video.Filename,
+			Status:       video.Status,
+			RejectReason: video.RejectReason,
+		})
+	}
+
+	return review, nil
+}
+
+// creatorArchiveReview 将稿件列表中的一项转为审核状态
+func creatorArchiveReview(item *CreatorArchiveItem) *ArchiveReview {
+	return &ArchiveReview{
+		Aid:          item.Archive.Aid,
+		Bvid:         item.Archive.Bvid,
+		Title:        item.Archive.Title,
+		State:        item.Archive.State,
+		StateDesc:    item.Archive.StateDesc,
+		RejectReason: item.Archive.RejectReason,
+		Videos:       item.Videos,
+	}
+}
+
+// DeleteArchive 删除稿件，删除后无法恢复
+func (c *Client) DeleteArchive(ctx context.Context, aid int64) (err error) {
+	ctx, span := c.startSpan(ctx, "DeleteArchive", Attr(AttrAid, aid))
+	defer func() { span.End(err) }()
+
+	return c.deleteArchive(ctx, aid)
+}
+
+// CreatorArchiveIterator 逐个遍历自己的稿件，用法同 bufio.Scanner
+//
+//	it := client.CreatorArchives(ctx, bilibili_go.ArchiveListRejected)
+//	for it.Next() {
+//		fmt.Println(it.Archive().Archive.Title)
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type CreatorArchiveIterator struct {
+	client  *Client
+	ctx     context.Context
+	status  []ArchiveListStatus
+	pn      int
+	ps      int
+	fetched int
+	total   int
+	items   []*CreatorArchiveItem
+	current *CreatorArchiveItem
+	done    bool
+	err     error
+}
+
+// CreatorArchives 遍历自己的稿件，statuses 为空时遍历全部
+func (c *Client) CreatorArchives(ctx context.Context, statuses ...ArchiveListStatus) *CreatorArchiveIterator {
+	return &CreatorArchiveIterator{client: c, ctx: ctx, status: statuses, ps: defaultCreatorArchivePageSize}
+}
+
+// PageSize 设置每页的数量，需要在第一次调用 Next 之前设置
+func (it *CreatorArchiveIterator) PageSize(ps int) *CreatorArchiveIterator {
+	if ps > 0 {
+		it.ps = ps
+	}
+
+	return it
+}
+
+// Next 获取下一个稿件，没有更多稿件或者出错时返回 false
+func (it *CreatorArchiveIterator) Next() bool {
+	for len(it.items) == 0 {
+		if it.done || it.err != nil {
+			it.current = nil
+			return false
+		}
+
+		it.pn++
+		resp, err := it.client.GetCreatorArchives(it.ctx, it.pn, it.ps, it.status...)
+		if err != nil {
+			it.err = err
+			continue
+		}
+
+		it.items = resp.ArcAudits
+		it.fetched += len(resp.ArcAudits)
+		it.total = resp.Page.Count
+		if len(resp.ArcAudits) == 0 || it.fetched >= it.total {
+			it.done = true
+		}
+	}
+
+	it.current, it.items = it.items[0], it.items[1:]
+
+	return true
+}
+
+// Archive 当前的稿件
+func (it *CreatorArchiveIterator) Archive() *CreatorArchiveItem {
+	return it.current
+}
+
+// Total 稿件总数，第一次调用 Next 之后有效
+func (it *CreatorArchiveIterator) Total() int {
+	return it.total
+}
+
+// Err 遍历过程中的错误
+func (it *CreatorArchiveIterator) Err() error {
+	return it.err
+}
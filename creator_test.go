@@ -0,0 +1,100 @@
+package bilibili_go
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestCreatorArchives(t *testing.T) {
+	var (
+		statuses []string
+		deleted  string
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/x/web/archives", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		statuses = append(statuses, query.Get("status"))
+		pn, _ := strconv.Atoi(query.Get("pn"))
+		ps, _ := strconv.Atoi(query.Get("ps"))
+
+		// 共5个稿件
+		var items []string
+		for aid := (pn-1)*ps + 1; aid <= pn*ps && aid <= 5; aid++ {
+			items = append(items, fmt.Sprintf(`{"Archive":{"aid":%d,"title":"v%d","state":-2,"reject_reason":"reason"},"Videos":[{"cid":%d}]}`, aid, aid, aid*10))
+		}
+		_, _ = fmt.Fprintf(w, `{"code":0,"data":{"class":{"not_pubed":5},"arc_audits":[%s],"page":{"pn":%d,"ps":%d,"count":5}}}`,
+			strings.Join(items, ","), pn, ps)
+	})
+	mux.HandleFunc("/x/vupre/web/archive/view", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":0,"data":{"archive":{"aid":1,"bvid":"BV1xx411c7mD","title":"v1","state":-2,` +
+			`"state_desc":"已退回","reject_reason":"标题违规"},"videos":[{"cid":10,"index":1,"title":"p1","status":-2,"reject_reason":"画面违规"}]}}`))
+	})
+	mux.HandleFunc("/x/web/archive/delete", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		deleted = r.PostForm.Get("aid")
+		_, _ = w.Write([]byte(`{"code":0}`))
+	})
+	client := newTestClient(t, mux)
+
+	it := client.CreatorArchives(context.Background(), ArchiveListReviewing, ArchiveListRejected).PageSize(2)
+	var aids []int64
+	for it.Next() {
+		aids = append(aids, it.Archive().Archive.Aid)
+		if !it.Archive().Archive.State.IsRejected() || it.Archive().Archive.RejectReason != "reason" {
+			t.Errorf("archive = %+v", it.Archive().Archive)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(aids) != 5 || aids[4] != 5 || it.Total() != 5 || len(statuses) != 3 || statuses[0] != "is_pubing,not_pubed" {
+		t.Errorf("aids = %v, total = %d, statuses = %v", aids, it.Total(), statuses)
+	}
+
+	review, err := client.GetArchiveReview(context.Background(), "BV1xx411c7mD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !review.State.IsRejected() || review.RejectReason != "标题违规" || len(review.Videos) != 1 || review.Videos[0].RejectReason != "画面违规" {
+		t.Errorf("review = %+v", review)
+	}
+
+	if err := client.DeleteArchive(context.Background(), 3); err != nil || deleted != "3" {
+		t.Errorf("DeleteArchive() = %v, deleted %q", err, deleted)
+	}
+}
+
+func TestArchiveState(t *testing.T) {
+	tests := []struct {
+		state                              ArchiveState
+		open, reviewing, transcode, reject bool
+	}{
+		{state: ArchiveStateOrange, open: true},
+		{state: ArchiveStateOpen, open: true},
+		{state: ArchiveStatePending, reviewing: true},
+		{state: ArchiveStateRepairPending, reviewing: true},
+		{state: ArchiveStateDeferred, reviewing: true},
+		{state: ArchiveStateDelayed, reviewing: true},
+		{state: ArchiveStateWaitTranscode, reviewing: true, transcode: true},
+		{state: ArchiveStateDistributing, reviewing: true, transcode: true},
+		{state: ArchiveStateSubmitted, reviewing: true, transcode: true},
+		{state: ArchiveStateTranscode, reviewing: true, transcode: true},
+		{state: ArchiveStateScheduled, reviewing: true},
+		{state: ArchiveStateSourceRepair, reject: true},
+		{state: ArchiveStateRecycled, reject: true},
+		{state: ArchiveStateTranscodeFailed, reject: true},
+		{state: ArchiveStateAdminLocked, reject: true},
+		{state: -99, reviewing: true}, // 未知状态视为审核中
+	}
+	for _, tt := range tests {
+		if tt.state.IsOpen() != tt.open || tt.state.IsReviewing() != tt.reviewing ||
+			tt.state.IsTranscoding() != tt.transcode || tt.state.IsRejected() != tt.reject {
+			t.Errorf("state %d: open=%v reviewing=%v transcoding=%v rejected=%v", tt.state,
+				tt.state.IsOpen(), tt.state.IsReviewing(), tt.state.IsTranscoding(), tt.state.IsRejected())
+		}
+	}
+}
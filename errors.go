@@ -0,0 +1,25 @@
+package bilibili_go
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// APIError 接口返回的业务码不为 CodeSuccess
+type APIError struct {
+	BaseResponse
+}
+
+// Error 返回完整的响应json
+func (e *APIError) Error() string {
+	bts, _ := json.Marshal(e.BaseResponse)
+
+	return string(bts)
+}
+
+// IsCode 判断 err 是否为指定业务码的 APIError
+func IsCode(err error, code Code) bool {
+	var apiErr *APIError
+
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
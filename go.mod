@@ -3,10 +3,12 @@ module github.com/kainhuck/bilibili-go
 go 1.20
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/PuerkitoBio/goquery v1.8.1
 	github.com/sirupsen/logrus v1.9.3
 	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/spf13/cast v1.5.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
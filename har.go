@@ -0,0 +1,491 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+	HAR 1.2 格式：http://www.softwareishard.com/blog/har-12-spec/
+*/
+
+// Har HAR 文档
+type Har struct {
+	Log *HarLog `json:"log"`
+}
+
+// HarLog HAR 文档根节点
+type HarLog struct {
+	Version string      `json:"version"`
+	Creator *HarCreator `json:"creator"`
+	Entries []*HarEntry `json:"entries"`
+}
+
+// HarCreator 生成 HAR 的程序
+type HarCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// HarEntry 一次请求和响应
+type HarEntry struct {
+	StartedDateTime time.Time    `json:"startedDateTime"`
+	Time            float64      `json:"time"` // 总耗时 单位毫秒
+	Request         *HarRequest  `json:"request"`
+	Response        *HarResponse `json:"response"`
+	Cache           struct{}     `json:"cache"`
+	Timings         *HarTimings  `json:"timings"`
+	Comment         string       `json:"comment,omitempty"`
+}
+
+// HarRequest 请求信息
+type HarRequest struct {
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*HarCookie    `json:"cookies"`
+	Headers     []*HarNameValue `json:"headers"`
+	QueryString []*HarNameValue `json:"queryString"`
+	PostData    *HarPostData    `json:"postData,omitempty"`
+	HeadersSize int64           `json:"headersSize"`
+	BodySize    int64           `json:"bodySize"`
+}
+
+// HarResponse 响应信息
+type HarResponse struct {
+	Status      int             `json:"status"`
+	StatusText  string          `json:"statusText"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*HarCookie    `json:"cookies"`
+	Headers     []*HarNameValue `json:"headers"`
+	Content     *HarContent     `json:"content"`
+	RedirectURL string          `json:"redirectURL"`
+	HeadersSize int64           `json:"headersSize"`
+	BodySize    int64           `json:"bodySize"`
+}
+
+// HarNameValue header 或者查询参数
+type HarNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HarCookie cookie
+type HarCookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Path     string     `json:"path,omitempty"`
+	Domain   string     `json:"domain,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	HTTPOnly bool       `json:"httpOnly,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+}
+
+// HarPostData 请求体
+type HarPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// HarContent 响应体
+type HarContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// HarTimings 耗时 单位毫秒
+type HarTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// HarOptions HAR 记录配置
+type HarOptions struct {
+	// MaxBodySize 二进制请求体和响应体（比如视频分片）最多记录的字节数，默认 1KB，小于0则不记录
+	MaxBodySize int
+
+	// DisableRedaction 关闭脱敏，默认会隐藏 cookie、csrf、refresh_token 以及 X-Upos-Auth 等认证信息
+	DisableRedaction bool
+}
+
+const (
+	harRedacted           = "[REDACTED]"
+	defaultHarMaxBodySize = 1024
+)
+
+var (
+	// 需要脱敏的 header
+	harSecretHeaders = map[string]bool{
+		"Cookie":        true,
+		"Set-Cookie":    true,
+		"X-Upos-Auth":   true,
+		"Authorization": true,
+	}
+
+	// 需要脱敏的参数名，会同时匹配 url 参数、表单以及 json 字段
+	harSecretParams = []string{"SESSDATA", "bili_jct", "DedeUserID__ckMd5", "csrf", "biliCSRF", "refresh_csrf", "refresh_token", "auth"}
+
+	harSecretQueryRegexp = regexp.MustCompile(`\b(` + strings.Join(harSecretParams, "|") + `)(=|%3D)([^&"\s;]*)`)
+	harSecretJSONRegexp  = regexp.MustCompile(`"(` + strings.Join(harSecretParams, "|") + `)"(\s*:\s*)"([^"]*)"`)
+)
+
+// harBuilder 将请求和响应转换为 HAR 记录
+type harBuilder struct {
+	opts HarOptions
+}
+
+func newHarBuilder(opts ...HarOptions) harBuilder {
+	b := harBuilder{opts: HarOptions{MaxBodySize: defaultHarMaxBodySize}}
+	if len(opts) != 0 {
+		b.opts = opts[0]
+		if b.opts.MaxBodySize == 0 {
+			b.opts.MaxBodySize = defaultHarMaxBodySize
+		}
+	}
+
+	return b
+}
+
+// middleware 返回记录请求的中间件，每个请求完成后调用 record
+func (b harBuilder) middleware(record func(entry *HarEntry)) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			start := time.Now()
+			harRequest := b.newRequest(request)
+
+			resp, err := next(request)
+			elapsed := float64(time.Since(start).Microseconds()) / 1000
+			entry := &HarEntry{
+				StartedDateTime: start,
+				Time:            elapsed,
+				Request:         harRequest,
+				Timings:         &HarTimings{Wait: elapsed},
+			}
+			if err != nil {
+				entry.Response = &HarResponse{Content: &HarContent{}}
+				entry.Comment = err.Error()
+				record(entry)
+
+				return resp, err
+			}
+
+			entry.Response, err = b.newResponse(resp)
+			if err != nil {
+				return nil, err
+			}
+			record(entry)
+
+			return resp, nil
+		}
+	}
+}
+
+// HarWriter 以 HAR 格式流式记录请求，所有请求记录完毕后需要调用 Close 补全文档结尾，
+// 未调用 Close 的文档也可以通过 LoadHar 加载
+type HarWriter struct {
+	harBuilder
+	mu      sync.Mutex
+	output  io.Writer
+	entries int
+	closed  bool
+	err     error       // 第一次写入失败的错误，之后不再写入
+	onError func(error) // 写入失败时调用一次
+}
+
+// NewHarWriter 创建 HarWriter，将 HAR 文档写入 output
+func NewHarWriter(output io.Writer, opts ...HarOptions) *HarWriter {
+	return &HarWriter{
+		harBuilder: newHarBuilder(opts...),
+		output:     output,
+	}
+}
+
+// Middleware 返回记录请求的中间件，可以通过 WithMiddleware 安装
+func (w *HarWriter) Middleware() Middleware {
+	return w.middleware(w.writeEntry)
+}
+
+// Err 返回写入失败的错误，写入失败后不再记录
+func (w *HarWriter) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.err
+}
+
+// Close 写入 HAR 文档结尾，之后的请求不再记录，之前写入失败时返回该错误
+func (w *HarWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return w.err
+	}
+	w.closed = true
+	if w.err != nil {
+		return w.err
+	}
+
+	if w.entries == 0 {
+		_, err := io.WriteString(w.output, harHeader())
+		if err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w.output, "\n]}}\n")
+
+	return err
+}
+
+func (w *HarWriter) writeEntry(entry *HarEntry) {
+	bts, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed || w.err != nil {
+		return
+	}
+	prefix := ",\n"
+	if w.entries == 0 {
+		prefix = harHeader()
+	}
+	if _, err = io.WriteString(w.output, prefix+string(bts)); err != nil {
+		w.err = err
+		if w.onError != nil {
+			w.onError(err)
+		}
+		return
+	}
+	w.entries++
+}
+
+func (b harBuilder) newRequest(request *http.Request) *HarRequest {
+	harRequest := &HarRequest{
+		Method:      request.Method,
+		URL:         b.redact(request.URL.String()),
+		HTTPVersion: request.Proto,
+		Cookies:     make([]*HarCookie, 0),
+		Headers:     b.headers(request.Header),
+		QueryString: make([]*HarNameValue, 0),
+		HeadersSize: -1,
+		BodySize:    request.ContentLength,
+	}
+
+	for _, cookie := range request.Cookies() {
+		harRequest.Cookies = append(harRequest.Cookies, b.cookie(cookie))
+	}
+
+	for key, values := range request.URL.Query() {
+		for _, value := range values {
+			harRequest.QueryString = append(harRequest.QueryString, &HarNameValue{
+				Name:  key,
+				Value: b.redactParam(key, value),
+			})
+		}
+	}
+
+	if request.Body != nil && request.GetBody != nil {
+		mimeType := request.Header.Get("Content-Type")
+		postData := &HarPostData{MimeType: mimeType}
+		if body, err := request.GetBody(); err == nil {
+			postData.Text, _, postData.Comment = b.body(body, mimeType)
+			_ = body.Close()
+		}
+		harRequest.PostData = postData
+	}
+
+	return harRequest
+}
+
+func (b harBuilder) newResponse(resp *http.Response) (*HarResponse, error) {
+	bodyBytes, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+
+	mimeType := resp.Header.Get("Content-Type")
+	content := &HarContent{
+		Size:     int64(len(bodyBytes)),
+		MimeType: mimeType,
+	}
+	content.Text, content.Encoding, content.Comment = b.body(bytes.NewReader(bodyBytes), mimeType)
+
+	harResponse := &HarResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     make([]*HarCookie, 0),
+		Headers:     b.headers(resp.Header),
+		Content:     content,
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    int64(len(bodyBytes)),
+	}
+	for _, cookie := range resp.Cookies() {
+		harResponse.Cookies = append(harResponse.Cookies, b.cookie(cookie))
+	}
+
+	return harResponse, nil
+}
+
+// body 读取报文，文本原样记录，二进制截断后以 base64 记录
+func (b harBuilder) body(reader io.Reader, mimeType string) (text string, encoding string, comment string) {
+	if isTextMimeType(mimeType) {
+		bts, _ := io.ReadAll(reader)
+
+		return b.redact(string(bts)), "", ""
+	}
+
+	if b.opts.MaxBodySize < 0 {
+		n, _ := io.Copy(io.Discard, reader)
+
+		return "", "", fmt.Sprintf("binary body omitted, %d bytes", n)
+	}
+
+	bts, _ := io.ReadAll(io.LimitReader(reader, int64(b.opts.MaxBodySize)))
+	rest, _ := io.Copy(io.Discard, reader)
+	if rest > 0 {
+		comment = fmt.Sprintf("truncated, %d bytes omitted", rest)
+	}
+
+	return base64.StdEncoding.EncodeToString(bts), "base64", comment
+}
+
+func (b harBuilder) headers(header http.Header) []*HarNameValue {
+	result := make([]*HarNameValue, 0, len(header))
+	for key, values := range header {
+		for _, value := range values {
+			if !b.opts.DisableRedaction && harSecretHeaders[http.CanonicalHeaderKey(key)] {
+				value = harRedacted
+			}
+			result = append(result, &HarNameValue{Name: key, Value: value})
+		}
+	}
+
+	return result
+}
+
+func (b harBuilder) cookie(cookie *http.Cookie) *HarCookie {
+	harCookie := &HarCookie{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Path:     cookie.Path,
+		Domain:   cookie.Domain,
+		HTTPOnly: cookie.HttpOnly,
+		Secure:   cookie.Secure,
+	}
+	if !cookie.Expires.IsZero() {
+		expires := cookie.Expires
+		harCookie.Expires = &expires
+	}
+	if !b.opts.DisableRedaction {
+		harCookie.Value = harRedacted
+	}
+
+	return harCookie
+}
+
+func (b harBuilder) redact(s string) string {
+	if b.opts.DisableRedaction {
+		return s
+	}
+
+	s = harSecretQueryRegexp.ReplaceAllString(s, "${1}${2}"+harRedacted)
+	s = harSecretJSONRegexp.ReplaceAllString(s, `"${1}"${2}"`+harRedacted+`"`)
+
+	return s
+}
+
+func (b harBuilder) redactParam(key string, value string) string {
+	if b.opts.DisableRedaction {
+		return value
+	}
+	for _, each := range harSecretParams {
+		if key == each {
+			return harRedacted
+		}
+	}
+
+	return b.redact(value)
+}
+
+func harHeader() string {
+	return `{"log":{"version":"1.2","creator":{"name":"bilibili-go","version":""},"entries":[` + "\n"
+}
+
+func isTextMimeType(mimeType string) bool {
+	if mimeType == "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return false
+	}
+
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+
+	switch mediaType {
+	case "application/json", "application/javascript", "application/xml", "application/x-www-form-urlencoded":
+		return true
+	}
+
+	return strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
+
+// LoadHar 加载 HAR 文档，兼容 HarWriter 未调用 Close 时写入的不完整文档
+func LoadHar(reader io.Reader) (*Har, error) {
+	bts, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	har := &Har{}
+	if err = json.Unmarshal(bts, har); err == nil {
+		return har, nil
+	}
+
+	// 补全未关闭的文档
+	trimmed := strings.TrimRight(string(bts), " \t\r\n,")
+	if !strings.HasSuffix(trimmed, "[") && !strings.HasSuffix(trimmed, "}") {
+		return nil, err
+	}
+	if jsonErr := json.Unmarshal([]byte(trimmed+"\n]}}"), har); jsonErr != nil {
+		return nil, err
+	}
+
+	return har, nil
+}
+
+// LoadHarFile 从文件加载 HAR 文档
+func LoadHarFile(file string) (*Har, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadHar(f)
+}
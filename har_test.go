@@ -0,0 +1,86 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kainhuck/bilibili-go/internal/net"
+)
+
+func TestHarWriter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "SESSDATA", Value: "new-sessdata"})
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"code":0,"data":{"refresh_token":"secret-token","auth":"secret-auth"}}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		opts    HarOptions
+		secrets []string
+	}{
+		{
+			"redacted",
+			HarOptions{MaxBodySize: 4},
+			nil,
+		},
+		{
+			"disable redaction",
+			HarOptions{MaxBodySize: 4, DisableRedaction: true},
+			[]string{"secret-csrf", "secret-token", "secret-auth", "upos-auth", "old-sessdata", "new-sessdata"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &bytes.Buffer{}
+			writer := NewHarWriter(output, tt.opts)
+
+			_, _, err := net.NewHttpClient(http.DefaultClient).
+				Use(writer.Middleware()).
+				Put(server.URL).
+				SetHeader("X-Upos-Auth", "upos-auth").
+				SetCookies([]*http.Cookie{{Name: "SESSDATA", Value: "old-sessdata"}}).
+				AddParams("csrf", "secret-csrf").
+				SetContentType("application/octet-stream").
+				SendBody(bytes.NewReader([]byte("0123456789"))).
+				End()
+			if err != nil {
+				t.Fatalf("End() error = %v", err)
+			}
+
+			// 未调用 Close 的文档也可以加载
+			har, err := LoadHar(bytes.NewReader(output.Bytes()))
+			if err != nil {
+				t.Fatalf("LoadHar() error = %v", err)
+			}
+			if len(har.Log.Entries) != 1 {
+				t.Fatalf("LoadHar() entries = %v, want 1", len(har.Log.Entries))
+			}
+			if got := har.Log.Entries[0].Request.PostData.Comment; got != "truncated, 6 bytes omitted" {
+				t.Errorf("PostData.Comment = %q", got)
+			}
+
+			dump := output.String()
+			for _, secret := range []string{"secret-csrf", "secret-token", "secret-auth", "upos-auth", "old-sessdata", "new-sessdata"} {
+				want := false
+				for _, each := range tt.secrets {
+					want = want || each == secret
+				}
+				if got := strings.Contains(dump, secret); got != want {
+					t.Errorf("contains %q = %v, want %v", secret, got, want)
+				}
+			}
+
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if _, err := LoadHar(bytes.NewReader(output.Bytes())); err != nil {
+				t.Errorf("LoadHar() after Close error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package bilibili_go
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strings"
+)
+
+// HeaderProfile 一组默认请求头，只会补充请求中未设置的header
+type HeaderProfile map[string]string
+
+// DefaultHeaderProfiles 各接口族的默认请求头，key 为接口名称（见 Endpoint）的前缀，按最长前缀匹配
+var DefaultHeaderProfiles = map[string]HeaderProfile{
+	"api.bilibili.com": {
+		"Referer":        "https://www.bilibili.com/",
+		"Origin":         "https://www.bilibili.com",
+		"Sec-Fetch-Site": "same-site",
+		"Sec-Fetch-Mode": "cors",
+		"Sec-Fetch-Dest": "empty",
+	},
+	"passport.bilibili.com": {
+		"Referer":        "https://www.bilibili.com/",
+		"Origin":         "https://www.bilibili.com",
+		"Sec-Fetch-Site": "same-site",
+		"Sec-Fetch-Mode": "cors",
+		"Sec-Fetch-Dest": "empty",
+	},
+	"account.bilibili.com": {
+		"Referer":        "https://account.bilibili.com/account/home",
+		"Sec-Fetch-Site": "same-origin",
+		"Sec-Fetch-Mode": "cors",
+		"Sec-Fetch-Dest": "empty",
+	},
+	"www.bilibili.com/correspond": {
+		"Referer":        "https://www.bilibili.com/",
+		"Sec-Fetch-Site": "same-origin",
+		"Sec-Fetch-Mode": "navigate",
+		"Sec-Fetch-Dest": "document",
+	},
+	"member.bilibili.com": {
+		"Referer":        "https://member.bilibili.com/platform/upload/video/frame",
+		"Origin":         "https://member.bilibili.com",
+		"Sec-Fetch-Site": "same-origin",
+		"Sec-Fetch-Mode": "cors",
+		"Sec-Fetch-Dest": "empty",
+	},
+	"upos": {
+		"Referer":        "https://member.bilibili.com/",
+		"Origin":         "https://member.bilibili.com",
+		"Sec-Fetch-Site": "cross-site",
+		"Sec-Fetch-Mode": "cors",
+		"Sec-Fetch-Dest": "empty",
+	},
+}
+
+type headersKey struct{}
+
+// WithHeaders 为 ctx 中发出的请求设置header，优先级高于默认请求头和 User-Agent，value 为空则删除该header，
+// 用于 XxxContext 方法单次调用时覆盖请求头
+func WithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	parent, _ := ctx.Value(headersKey{}).(map[string]string)
+	merged := make(map[string]string, len(parent)+len(headers))
+	for key, value := range parent {
+		merged[key] = value
+	}
+	for key, value := range headers {
+		merged[key] = value
+	}
+
+	return context.WithValue(ctx, headersKey{}, merged)
+}
+
+// headerProfile 按最长前缀匹配请求头
+func (c *Client) headerProfile(endpoint string) HeaderProfile {
+	var matched string
+	for prefix := range c.headerProfiles {
+		if strings.HasPrefix(endpoint, prefix) && len(prefix) > len(matched) {
+			matched = prefix
+		}
+	}
+
+	return c.headerProfiles[matched]
+}
+
+// pickUserAgent 从 User-Agent 池中随机选择一个，每个 Client 只选择一次
+func pickUserAgent(uas []string) string {
+	if len(uas) == 0 {
+		return ""
+	}
+
+	return uas[rand.Intn(len(uas))]
+}
+
+// headerMiddleware 设置默认请求头、User-Agent 以及单次调用的覆盖
+func (c *Client) headerMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			request = request.Clone(request.Context())
+
+			for key, value := range c.headerProfile(Endpoint(request.URL)) {
+				if request.Header.Get(key) == "" {
+					request.Header.Set(key, value)
+				}
+			}
+
+			if c.userAgent != "" {
+				request.Header.Set("User-Agent", c.userAgent)
+			}
+
+			overrides, _ := request.Context().Value(headersKey{}).(map[string]string)
+			for key, value := range overrides {
+				if value == "" {
+					request.Header.Del(key)
+				} else {
+					request.Header.Set(key, value)
+				}
+			}
+
+			return next(request)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package bilibili_go
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestHeaderProfiles(t *testing.T) {
+	var received http.Header
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		_, _ = w.Write([]byte(`{"code":0}`))
+	})
+	client := newTestClient(t, handler, WithUserAgentPool("ua-1", "ua-2", "ua-3"))
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		uri  string
+		want map[string]string
+	}{
+		{
+			name: "member",
+			ctx:  context.Background(),
+			uri:  "https://member.bilibili.com/x/vu/web/add/v3",
+			want: map[string]string{"Origin": "https://member.bilibili.com", "Sec-Fetch-Site": "same-origin"},
+		},
+		{
+			name: "correspond",
+			ctx:  context.Background(),
+			uri:  "https://www.bilibili.com/correspond/1/abc",
+			want: map[string]string{"Sec-Fetch-Mode": "navigate", "Origin": ""},
+		},
+		{
+			name: "override",
+			ctx:  WithHeaders(context.Background(), map[string]string{"Referer": "https://space.bilibili.com/", "Origin": ""}),
+			uri:  "https://api.bilibili.com/x/space/wbi/acc/info",
+			want: map[string]string{"Referer": "https://space.bilibili.com/", "Origin": "", "Sec-Fetch-Site": "same-site"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := client.getHttpClient(false).SetContext(tt.ctx).Get(tt.uri).End(); err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tt.want {
+				if got := received.Get(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+			if got := received.Get("User-Agent"); got != client.userAgent {
+				t.Errorf("User-Agent = %q, want %q", got, client.userAgent)
+			}
+		})
+	}
+
+	// 登录后继续使用同一个 User-Agent
+	before := received.Get("User-Agent")
+	client.setMid(42)
+	if _, _, err := client.getHttpClient(false).Get("https://api.bilibili.com/x/web-interface/nav").End(); err != nil {
+		t.Fatal(err)
+	}
+	if got := received.Get("User-Agent"); got != before || got == "" {
+		t.Errorf("User-Agent after login = %q, want %q", got, before)
+	}
+}
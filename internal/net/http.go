@@ -1,16 +1,16 @@
 package net
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 )
 
 type HttpClient struct {
+	ctx         context.Context
 	httpClient  *http.Client
 	method      string
 	params      url.Values // 查询参数
@@ -21,20 +21,19 @@ type HttpClient struct {
 	cookies     []*http.Cookie
 	contentType string // 指定Content-Type
 	userAgent   string // 指定User-Agent
-	debug       bool
-	debugOutput *os.File
 	wbiKey      string
+	middlewares []Middleware
 }
 
 func NewHttpClient(client *http.Client) *HttpClient {
 	return &HttpClient{
-		httpClient:  client,
-		method:      http.MethodGet,
-		params:      make(url.Values),
-		formData:    make(url.Values),
-		header:      make(map[string]string),
-		cookies:     make([]*http.Cookie, 0),
-		debugOutput: os.Stdout,
+		ctx:        context.Background(),
+		httpClient: client,
+		method:     http.MethodGet,
+		params:     make(url.Values),
+		formData:   make(url.Values),
+		header:     make(map[string]string),
+		cookies:    make([]*http.Cookie, 0),
 	}
 }
 
@@ -58,6 +57,7 @@ func (c *HttpClient) Clone() *HttpClient {
 	copy(clonedCookies, c.cookies)
 
 	return &HttpClient{
+		ctx:         c.ctx,
 		httpClient:  c.httpClient,
 		method:      c.method,
 		params:      clonedParams,
@@ -68,9 +68,8 @@ func (c *HttpClient) Clone() *HttpClient {
 		cookies:     clonedCookies,
 		contentType: c.contentType,
 		userAgent:   c.userAgent,
-		debug:       c.debug,
-		debugOutput: c.debugOutput,
 		wbiKey:      c.wbiKey,
+		middlewares: append([]Middleware(nil), c.middlewares...),
 	}
 }
 
@@ -95,6 +94,14 @@ func (c *HttpClient) Put(uri string) *HttpClient {
 	return c
 }
 
+// Method 使用任意 method 请求
+func (c *HttpClient) Method(method string, uri string) *HttpClient {
+	c.method = method
+	c.uri = uri
+
+	return c
+}
+
 func (c *HttpClient) SetContentType(contentType string) *HttpClient {
 	c.contentType = contentType
 
@@ -143,34 +150,40 @@ func (c *HttpClient) SetCookies(cookies []*http.Cookie) *HttpClient {
 	return c
 }
 
+// SetContext 设置请求的ctx
+func (c *HttpClient) SetContext(ctx context.Context) *HttpClient {
+	c.ctx = ctx
+
+	return c
+}
+
 func (c *HttpClient) SetWbiKey(wbiKey string) *HttpClient {
-	c.wbiKey = getMixinKey(wbiKey)
+	c.wbiKey = wbiKey
 
 	return c
 }
 
-func (c *HttpClient) Debug(output *os.File) *HttpClient {
-	c.debug = true
-	if output != nil {
-		c.debugOutput = output
-	}
+// Use 添加中间件，先添加的中间件位于外层
+func (c *HttpClient) Use(middlewares ...Middleware) *HttpClient {
+	c.middlewares = append(c.middlewares, middlewares...)
 
 	return c
 }
 
-func (c *HttpClient) End() (resp *http.Response, body []byte, err error) {
+// newRequest 根据当前配置构造请求，包括wbi签名
+func (c *HttpClient) newRequest() (*http.Request, error) {
 	if len(c.formData) > 0 {
 		c.body = strings.NewReader(c.formData.Encode())
 		c.contentType = "application/x-www-form-urlencoded"
 	}
 
-	request, err := http.NewRequest(c.method, c.uri, c.body)
+	request, err := http.NewRequestWithContext(c.ctx, c.method, c.uri, c.body)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	if c.wbiKey != "" {
-		encWbi(c.params, c.wbiKey)
+		EncWbi(c.params, c.wbiKey)
 	}
 
 	if len(c.params) > 0 {
@@ -193,26 +206,26 @@ func (c *HttpClient) End() (resp *http.Response, body []byte, err error) {
 		request.Header.Add("User-Agent", c.userAgent)
 	}
 
-	if c.debug {
-		_, _ = fmt.Fprintf(c.debugOutput, ">>> REQUEST\n")
-		_, _ = fmt.Fprintf(c.debugOutput, "-------------- start -------------\n")
-		_, _ = fmt.Fprintf(c.debugOutput, "%s\n", HttpDumpRequest(request))
-		_, _ = fmt.Fprintf(c.debugOutput, "--------------  end  -------------\n\n\n")
+	return request, nil
+}
+
+// roundTrip 返回经过所有中间件包装后的发送函数
+func (c *HttpClient) roundTrip() RoundTripFunc {
+	return Chain(c.httpClient.Do, c.middlewares...)
+}
+
+func (c *HttpClient) End() (resp *http.Response, body []byte, err error) {
+	request, err := c.newRequest()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	resp, err = c.httpClient.Do(request)
+	resp, err = c.roundTrip()(request)
 	if err != nil {
 		return
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if c.debug {
-		_, _ = fmt.Fprintf(c.debugOutput, ">>> RESPONSE\n")
-		_, _ = fmt.Fprintf(c.debugOutput, "-------------- start -------------\n")
-		_, _ = fmt.Fprintf(c.debugOutput, "%s\n", HttpDumpResponse(resp))
-		_, _ = fmt.Fprintf(c.debugOutput, "--------------  end  -------------\n\n\n")
-	}
-
 	body, err = io.ReadAll(resp.Body)
 
 	return
@@ -0,0 +1,19 @@
+package net
+
+import "net/http"
+
+// RoundTripFunc 发送一个请求并返回响应
+type RoundTripFunc func(request *http.Request) (*http.Response, error)
+
+// Middleware 请求中间件，通过包装 next 可以在请求发送前后插入自定义逻辑，
+// 也可以不调用 next 直接返回响应
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// Chain 将中间件按顺序包装到 last 上，第一个中间件位于最外层
+func Chain(last RoundTripFunc, middlewares ...Middleware) RoundTripFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		last = middlewares[i](last)
+	}
+
+	return last
+}
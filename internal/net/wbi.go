@@ -1,68 +1,20 @@
 package net
 
 import (
-	"crypto/md5"
-	"encoding/hex"
+	"github.com/kainhuck/bilibili-go/wbi"
 	"net/url"
-	"sort"
-	"strconv"
-	"strings"
-	"time"
 )
 
-var (
-	mixinKeyEncTab = []int{
-		46, 47, 18, 2, 53, 8, 23, 32, 15, 50, 10, 31, 58, 3, 45, 35, 27, 43, 5, 49,
-		33, 9, 42, 19, 29, 28, 14, 39, 12, 38, 41, 13, 37, 48, 7, 16, 24, 55, 40,
-		61, 26, 17, 0, 1, 60, 51, 30, 4, 22, 25, 54, 21, 56, 59, 6, 63, 57, 62, 11,
-		36, 20, 34, 44, 52,
+// EncWbi 使用 imgKey + subKey 重新签名，会替换已有的 wts 和 w_rid
+func EncWbi(params url.Values, wbiKey string) bool {
+	if len(wbiKey) < 64 {
+		return false
 	}
-)
-
-func encWbi(params url.Values, wbiKey string) {
-	currTime := strconv.FormatInt(time.Now().Unix(), 10)
-	params.Add("wts", currTime)
-
-	// Sort keys
-	keys := make([]string, 0, len(params))
-	for k := range params {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
-
-	// Remove unwanted characters
-	for k := range params {
-		params.Set(k, sanitizeString(params.Get(k)))
-	}
-
-	// Build URL parameters
-	query := url.Values{}
-	for _, k := range keys {
-		query.Set(k, params.Get(k))
+	signer, err := wbi.NewSigner(wbiKey[:32], wbiKey[32:])
+	if err != nil {
+		return false
 	}
-	queryStr := query.Encode()
+	signer.Sign(params)
 
-	// Calculate w_rid
-	hash := md5.Sum([]byte(queryStr + wbiKey))
-	params.Set("w_rid", hex.EncodeToString(hash[:]))
-
-	return
-}
-
-func getMixinKey(orig string) string {
-	var str strings.Builder
-	for _, v := range mixinKeyEncTab {
-		if v < len(orig) {
-			str.WriteByte(orig[v])
-		}
-	}
-	return str.String()[:32]
-}
-
-func sanitizeString(s string) string {
-	unwantedChars := []string{"!", "'", "(", ")", "*"}
-	for _, char := range unwantedChars {
-		s = strings.ReplaceAll(s, char, "")
-	}
-	return s
+	return true
 }
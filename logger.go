@@ -1,5 +1,16 @@
 package bilibili_go
 
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"strings"
+	"time"
+)
+
 type Logger interface {
 	Debug(args ...any)
 	Info(args ...any)
@@ -10,3 +21,200 @@ type Logger interface {
 	Warnf(format string, args ...any)
 	Errorf(format string, args ...any)
 }
+
+// LogLevel 日志等级
+type LogLevel int
+
+const (
+	LevelDebug LogLevel = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// Subsystem 日志所属的子系统，可以分别设置日志等级
+type Subsystem string
+
+const (
+	// SubsystemAuth 登陆、退出以及cookie刷新
+	SubsystemAuth Subsystem = "auth"
+	// SubsystemUpload 视频和封面上传
+	SubsystemUpload Subsystem = "upload"
+	// SubsystemHTTP 每个http请求，请求日志为 debug 级别，失败为 warn 级别
+	SubsystemHTTP Subsystem = "http"
+)
+
+// 日志字段名
+const (
+	FieldSubsystem = "subsystem"
+	FieldRequestID = "request_id"
+	FieldEndpoint  = "endpoint"
+	FieldMid       = "mid"
+	FieldUploadID  = "upload_id"
+	FieldError     = "error"
+)
+
+// Field 日志字段
+type Field struct {
+	Key   string
+	Value any
+}
+
+// KV 创建日志字段
+func KV(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// StructuredLogger 结构化日志接口，每条日志都会带上 subsystem、request_id、mid 等字段
+type StructuredLogger interface {
+	Log(level LogLevel, msg string, fields ...Field)
+}
+
+// printfLogger 将结构化日志转为 printf 风格，字段以 key=value 的形式追加到消息后
+type printfLogger struct {
+	logger Logger
+}
+
+func (l printfLogger) Log(level LogLevel, msg string, fields ...Field) {
+	var buf strings.Builder
+	buf.WriteString(msg)
+	for _, field := range fields {
+		_, _ = fmt.Fprintf(&buf, " %s=%v", field.Key, field.Value)
+	}
+
+	switch level {
+	case LevelDebug:
+		l.logger.Debug(buf.String())
+	case LevelInfo:
+		l.logger.Info(buf.String())
+	case LevelWarn:
+		l.logger.Warn(buf.String())
+	default:
+		l.logger.Error(buf.String())
+	}
+}
+
+// logrusLogger logrus 适配
+type logrusLogger struct {
+	logger logrus.FieldLogger
+}
+
+// NewLogrusLogger 使用 logrus 输出结构化日志
+func NewLogrusLogger(logger logrus.FieldLogger) StructuredLogger {
+	return logrusLogger{logger: logger}
+}
+
+func (l logrusLogger) Log(level LogLevel, msg string, fields ...Field) {
+	logrusFields := make(logrus.Fields, len(fields))
+	for _, field := range fields {
+		logrusFields[field.Key] = field.Value
+	}
+	entry := l.logger.WithFields(logrusFields)
+
+	switch level {
+	case LevelDebug:
+		entry.Debug(msg)
+	case LevelInfo:
+		entry.Info(msg)
+	case LevelWarn:
+		entry.Warn(msg)
+	default:
+		entry.Error(msg)
+	}
+}
+
+// toStructuredLogger 将 printf 风格的 Logger 转为 StructuredLogger，logrus 直接使用字段输出
+func toStructuredLogger(logger Logger) StructuredLogger {
+	if fieldLogger, ok := logger.(logrus.FieldLogger); ok {
+		return NewLogrusLogger(fieldLogger)
+	}
+
+	return printfLogger{logger: logger}
+}
+
+type logFieldsKey struct{}
+
+// withLogFields 为 ctx 中后续输出的日志附加字段
+func withLogFields(ctx context.Context, fields ...Field) context.Context {
+	parent, _ := ctx.Value(logFieldsKey{}).([]Field)
+
+	return context.WithValue(ctx, logFieldsKey{}, append(append([]Field(nil), parent...), fields...))
+}
+
+// withRequestID 如果 ctx 中没有 request_id 则生成一个，同一个操作中的所有日志共用一个 request_id
+func withRequestID(ctx context.Context) context.Context {
+	fields, _ := ctx.Value(logFieldsKey{}).([]Field)
+	for _, field := range fields {
+		if field.Key == FieldRequestID {
+			return ctx
+		}
+	}
+
+	return withLogFields(ctx, KV(FieldRequestID, newRequestID()))
+}
+
+func newRequestID() string {
+	bts := make([]byte, 8)
+	_, _ = rand.Read(bts)
+
+	return hex.EncodeToString(bts)
+}
+
+// log 输出日志，会过滤掉低于子系统日志等级的日志
+func (c *Client) log(ctx context.Context, subsystem Subsystem, level LogLevel, msg string, fields ...Field) {
+	if level < c.logLevel(subsystem) {
+		return
+	}
+
+	ctxFields, _ := ctx.Value(logFieldsKey{}).([]Field)
+	all := make([]Field, 0, len(ctxFields)+len(fields)+2)
+	all = append(all, KV(FieldSubsystem, subsystem), KV(FieldMid, c.getMid()))
+	all = append(all, ctxFields...)
+	all = append(all, fields...)
+
+	c.logger.Log(level, msg, all...)
+}
+
+func (c *Client) logLevel(subsystem Subsystem) LogLevel {
+	if level, ok := c.logLevels[subsystem]; ok {
+		return level
+	}
+
+	return LevelInfo
+}
+
+// loggingMiddleware 记录每个http请求
+func (c *Client) loggingMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			ctx := withLogFields(withRequestID(request.Context()), KV(FieldEndpoint, Endpoint(request.URL)))
+			start := time.Now()
+
+			resp, err := next(request)
+			if err != nil {
+				c.log(ctx, SubsystemHTTP, LevelWarn, "http request failed",
+					KV("method", request.Method), KV("duration", time.Since(start)), KV(FieldError, err))
+				return resp, err
+			}
+			c.log(ctx, SubsystemHTTP, LevelDebug, "http request",
+				KV("method", request.Method), KV("status", resp.StatusCode), KV("duration", time.Since(start)))
+
+			return resp, nil
+		}
+	}
+}
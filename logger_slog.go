@@ -0,0 +1,40 @@
+//go:build go1.21
+
+package bilibili_go
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogLogger log/slog 适配
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger 使用 log/slog 输出结构化日志
+func NewSlogLogger(logger *slog.Logger) StructuredLogger {
+	return slogLogger{logger: logger}
+}
+
+func (l slogLogger) Log(level LogLevel, msg string, fields ...Field) {
+	attrs := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		attrs = append(attrs, slog.Any(field.Key, field.Value))
+	}
+
+	l.logger.LogAttrs(context.Background(), toSlogLevel(level), msg, attrs...)
+}
+
+func toSlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	}
+
+	return slog.LevelError
+}
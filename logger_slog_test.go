@@ -0,0 +1,31 @@
+//go:build go1.21
+
+package bilibili_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	for _, level := range []LogLevel{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+		buf.Reset()
+		logger.Log(level, "http request", KV(FieldSubsystem, SubsystemHTTP), KV(FieldRequestID, "abc"))
+
+		var record map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("unmarshal %q: %v", buf.String(), err)
+		}
+		if record["level"] != toSlogLevel(level).String() || record["msg"] != "http request" {
+			t.Errorf("level %s record = %v", level, record)
+		}
+		if record[FieldSubsystem] != "http" || record[FieldRequestID] != "abc" {
+			t.Errorf("level %s fields = %v", level, record)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package bilibili_go
+
+import (
+	"context"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// memoryLogger 记录所有日志
+type memoryLogger struct {
+	mu      sync.Mutex
+	entries []memoryLogEntry
+}
+
+type memoryLogEntry struct {
+	level  LogLevel
+	msg    string
+	fields []Field
+}
+
+func (l *memoryLogger) Log(level LogLevel, msg string, fields ...Field) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, memoryLogEntry{level: level, msg: msg, fields: fields})
+}
+
+func (l *memoryLogger) messages() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	msgs := make([]string, 0, len(l.entries))
+	for _, entry := range l.entries {
+		msgs = append(msgs, entry.msg)
+	}
+
+	return msgs
+}
+
+func (e memoryLogEntry) field(key string) any {
+	for _, field := range e.fields {
+		if field.Key == key {
+			return field.Value
+		}
+	}
+
+	return nil
+}
+
+func TestLogLevel(t *testing.T) {
+	logger := &memoryLogger{}
+	client := NewClient(WithStructuredLogger(logger), WithRefreshInterval(0),
+		WithLogLevel(LevelWarn),
+		WithLogLevel(LevelDebug, SubsystemHTTP),
+		WithLogLevel(LevelError, SubsystemUpload))
+
+	ctx := context.Background()
+	for _, subsystem := range []Subsystem{SubsystemAuth, SubsystemUpload, SubsystemHTTP} {
+		for _, level := range []LogLevel{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+			client.log(ctx, subsystem, level, fmt.Sprintf("%s %s", subsystem, level))
+		}
+	}
+
+	want := []string{
+		"auth warn", "auth error",
+		"upload error",
+		"http debug", "http info", "http warn", "http error",
+	}
+	if got := logger.messages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("messages = %v, want %v", got, want)
+	}
+	for _, entry := range logger.entries {
+		if entry.field(FieldSubsystem) == nil || entry.field(FieldMid) == nil {
+			t.Errorf("entry %q missing subsystem or mid: %+v", entry.msg, entry.fields)
+		}
+	}
+
+	// 默认等级为 info
+	logger = &memoryLogger{}
+	client = NewClient(WithStructuredLogger(logger), WithRefreshInterval(0))
+	client.log(ctx, SubsystemAuth, LevelDebug, "debug")
+	client.log(ctx, SubsystemAuth, LevelInfo, "info")
+	if got := logger.messages(); !reflect.DeepEqual(got, []string{"info"}) {
+		t.Errorf("default level messages = %v", got)
+	}
+}
+
+func TestLogRequestID(t *testing.T) {
+	logger := &memoryLogger{}
+	client := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":0}`))
+	}), WithStructuredLogger(logger), WithLogLevel(LevelDebug, SubsystemHTTP))
+
+	// 同一个操作中的请求共用 request_id，已有的 request_id 不会被覆盖
+	ctx := withRequestID(context.Background())
+	if ctx != withRequestID(ctx) {
+		t.Error("withRequestID should keep existing request_id")
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.getHttpClient(false).SetContext(ctx).Get("https://api.bilibili.com/x/test").End(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 没有 request_id 的请求各自生成
+	if _, _, err := client.getHttpClient(false).Get("https://api.bilibili.com/x/test").End(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logger.entries) != 3 {
+		t.Fatalf("entries = %d, want 3", len(logger.entries))
+	}
+	ids := make([]any, 0, len(logger.entries))
+	for _, entry := range logger.entries {
+		if entry.msg != "http request" || entry.field(FieldSubsystem) != SubsystemHTTP {
+			t.Errorf("unexpected entry %q %+v", entry.msg, entry.fields)
+		}
+		if entry.field(FieldEndpoint) != "api.bilibili.com/x/test" {
+			t.Errorf("endpoint = %v", entry.field(FieldEndpoint))
+		}
+		ids = append(ids, entry.field(FieldRequestID))
+	}
+	if ids[0] == nil || ids[0] != ids[1] {
+		t.Errorf("request_id = %v, want shared in one operation", ids)
+	}
+	if ids[2] == nil || ids[2] == ids[0] {
+		t.Errorf("request_id = %v, want new id for new operation", ids)
+	}
+}
+
+// printfRecorder 记录 printf 风格的日志
+type printfRecorder struct {
+	lines []string
+}
+
+func (p *printfRecorder) Debug(args ...any) { p.lines = append(p.lines, "debug "+fmt.Sprint(args...)) }
+func (p *printfRecorder) Info(args ...any)  { p.lines = append(p.lines, "info "+fmt.Sprint(args...)) }
+func (p *printfRecorder) Warn(args ...any)  { p.lines = append(p.lines, "warn "+fmt.Sprint(args...)) }
+func (p *printfRecorder) Error(args ...any) { p.lines = append(p.lines, "error "+fmt.Sprint(args...)) }
+func (p *printfRecorder) Debugf(format string, args ...any) {
+	p.Debug(fmt.Sprintf(format, args...))
+}
+func (p *printfRecorder) Infof(format string, args ...any) {
+	p.Info(fmt.Sprintf(format, args...))
+}
+func (p *printfRecorder) Warnf(format string, args ...any) {
+	p.Warn(fmt.Sprintf(format, args...))
+}
+func (p *printfRecorder) Errorf(format string, args ...any) {
+	p.Error(fmt.Sprintf(format, args...))
+}
+
+func TestLoggerAdapters(t *testing.T) {
+	printf := &printfRecorder{}
+	logger := toStructuredLogger(printf)
+	logger.Log(LevelWarn, "upload failed", KV(FieldUploadID, "u1"), KV(FieldError, "timeout"))
+	logger.Log(LevelDebug, "chunk")
+	if want := []string{"warn upload failed upload_id=u1 error=timeout", "debug chunk"}; !reflect.DeepEqual(printf.lines, want) {
+		t.Errorf("printf lines = %q, want %q", printf.lines, want)
+	}
+
+	// logrus 直接使用字段输出
+	nullLogger, hook := test.NewNullLogger()
+	nullLogger.SetLevel(logrus.DebugLevel)
+	logger = toStructuredLogger(nullLogger)
+	if _, ok := logger.(logrusLogger); !ok {
+		t.Fatalf("toStructuredLogger(logrus) = %T, want logrusLogger", logger)
+	}
+	logger.Log(LevelError, "refresh failed", KV(FieldMid, int64(2)))
+
+	entry := hook.LastEntry()
+	if entry == nil || entry.Level != logrus.ErrorLevel || entry.Message != "refresh failed" || entry.Data[FieldMid] != int64(2) {
+		t.Errorf("logrus entry = %+v", entry)
+	}
+}
@@ -0,0 +1,83 @@
+package bilibili_go
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Metrics 指标上报接口，可以对接 prometheus 等监控系统，参考 metrics/prometheus
+type Metrics interface {
+	// ObserveRequest 每个http请求完成后调用，endpoint 为接口地址（见 Endpoint），请求失败时 status 为 0
+	ObserveRequest(endpoint string, method string, status int, duration time.Duration)
+
+	// ObserveCode 接口返回的业务码
+	ObserveCode(endpoint string, code Code)
+
+	// IncRetry 请求重试
+	IncRetry(endpoint string)
+
+	// ObserveUploadChunk 视频分片上传完成
+	ObserveUploadChunk(size int, duration time.Duration, err error)
+
+	// ObserveUpload 视频上传完成
+	ObserveUpload(size int64, duration time.Duration, err error)
+
+	// ObserveRefresh cookie刷新结果
+	ObserveRefresh(err error)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveRequest(string, string, int, time.Duration) {}
+
+func (nopMetrics) ObserveCode(string, Code) {}
+
+func (nopMetrics) IncRetry(string) {}
+
+func (nopMetrics) ObserveUploadChunk(int, time.Duration, error) {}
+
+func (nopMetrics) ObserveUpload(int64, time.Duration, error) {}
+
+func (nopMetrics) ObserveRefresh(error) {}
+
+// Endpoint 返回请求对应的接口名称，格式为 host + path，
+// 视频上传的 upos 地址包含文件名，统一归为 upos，避免指标维度过多
+func Endpoint(u *url.URL) string {
+	switch {
+	case strings.HasSuffix(u.Host, ".bilivideo.com"):
+		return "upos"
+	case strings.HasPrefix(u.Path, "/correspond/"):
+		return u.Host + "/correspond"
+	}
+
+	return u.Host + u.Path
+}
+
+// metricsMiddleware 统计请求耗时、状态码以及业务码
+func metricsMiddleware(metrics Metrics) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			endpoint := Endpoint(request.URL)
+			start := time.Now()
+
+			resp, err := next(request)
+			if err != nil {
+				metrics.ObserveRequest(endpoint, request.Method, 0, time.Since(start))
+				return resp, err
+			}
+			metrics.ObserveRequest(endpoint, request.Method, resp.StatusCode, time.Since(start))
+
+			code, err := peekCode(resp)
+			if err != nil {
+				return nil, err
+			}
+			if code != nil {
+				metrics.ObserveCode(endpoint, *code)
+			}
+
+			return resp, nil
+		}
+	}
+}
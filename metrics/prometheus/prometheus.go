@@ -0,0 +1,129 @@
+// Package prometheus 将 bilibili_go.Metrics 上报到 prometheus
+//
+//	metrics, err := prometheus.NewMetrics(prom.DefaultRegisterer, "bilibili")
+//	if err != nil {
+//		panic(err)
+//	}
+//
+//	client := bilibili_go.NewClient(
+//		bilibili_go.WithMetrics(metrics),
+//	)
+package prometheus
+
+import (
+	"strconv"
+	"time"
+
+	bilibili_go "github.com/kainhuck/bilibili-go"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+var _ bilibili_go.Metrics = (*Metrics)(nil)
+
+// Metrics 实现 bilibili_go.Metrics
+type Metrics struct {
+	requests        *prom.CounterVec
+	requestDuration *prom.HistogramVec
+	codes           *prom.CounterVec
+	retries         *prom.CounterVec
+	uploadBytes     *prom.CounterVec
+	uploadDuration  *prom.HistogramVec
+	uploadSpeed     prom.Histogram
+	refreshes       *prom.CounterVec
+}
+
+// NewMetrics 创建指标并注册到 registerer，namespace 为指标名前缀
+func NewMetrics(registerer prom.Registerer, namespace string) (*Metrics, error) {
+	m := &Metrics{
+		requests: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Total number of http requests by endpoint, method and status.",
+		}, []string{"endpoint", "method", "status"}),
+		requestDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Http request latency by endpoint.",
+			Buckets:   prom.DefBuckets,
+		}, []string{"endpoint"}),
+		codes: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "response_codes_total",
+			Help:      "Total number of bilibili business codes by endpoint.",
+		}, []string{"endpoint", "code"}),
+		retries: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "Total number of retried requests by endpoint.",
+		}, []string{"endpoint"}),
+		uploadBytes: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "upload_bytes_total",
+			Help:      "Total number of uploaded video bytes by result.",
+		}, []string{"result"}),
+		uploadDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upload_duration_seconds",
+			Help:      "Video upload duration by result.",
+			Buckets:   prom.ExponentialBuckets(1, 2, 12),
+		}, []string{"result"}),
+		uploadSpeed: prom.NewHistogram(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upload_chunk_throughput_bytes_per_second",
+			Help:      "Throughput of successfully uploaded video chunks.",
+			Buckets:   prom.ExponentialBuckets(64*1024, 2, 10),
+		}),
+		refreshes: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_refreshes_total",
+			Help:      "Total number of cookie refreshes by result.",
+		}, []string{"result"}),
+	}
+
+	for _, collector := range []prom.Collector{
+		m.requests, m.requestDuration, m.codes, m.retries,
+		m.uploadBytes, m.uploadDuration, m.uploadSpeed, m.refreshes,
+	} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (m *Metrics) ObserveRequest(endpoint string, method string, status int, duration time.Duration) {
+	m.requests.WithLabelValues(endpoint, method, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveCode(endpoint string, code bilibili_go.Code) {
+	m.codes.WithLabelValues(endpoint, strconv.Itoa(int(code))).Inc()
+}
+
+func (m *Metrics) IncRetry(endpoint string) {
+	m.retries.WithLabelValues(endpoint).Inc()
+}
+
+func (m *Metrics) ObserveUploadChunk(size int, duration time.Duration, err error) {
+	m.uploadBytes.WithLabelValues(result(err)).Add(float64(size))
+	if err == nil && duration > 0 {
+		m.uploadSpeed.Observe(float64(size) / duration.Seconds())
+	}
+}
+
+func (m *Metrics) ObserveUpload(_ int64, duration time.Duration, err error) {
+	m.uploadDuration.WithLabelValues(result(err)).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveRefresh(err error) {
+	m.refreshes.WithLabelValues(result(err)).Inc()
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+
+	return "success"
+}
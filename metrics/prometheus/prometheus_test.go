@@ -0,0 +1,67 @@
+package prometheus
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	bilibili_go "github.com/kainhuck/bilibili-go"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type roundTripFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestMetrics(t *testing.T) {
+	registry := prom.NewRegistry()
+	metrics, err := NewMetrics(registry, "bilibili")
+	if err != nil {
+		t.Fatalf("NewMetrics() error = %v", err)
+	}
+
+	client := bilibili_go.NewClient(
+		bilibili_go.WithRefreshInterval(0),
+		bilibili_go.WithMetrics(metrics),
+		bilibili_go.WithHttpClient(&http.Client{
+			Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"code":-101,"message":"账号未登录"}`)),
+					Request:    request,
+				}, nil
+			}),
+		}),
+	)
+
+	if _, err := client.GetNavigation(); err == nil {
+		t.Fatalf("GetNavigation() should fail with code -101")
+	}
+
+	endpoint := "api.bilibili.com/x/web-interface/nav"
+	tests := []struct {
+		name      string
+		collector prom.Collector
+		want      float64
+	}{
+		{"requests", metrics.requests.WithLabelValues(endpoint, http.MethodGet, "200"), 1},
+		{"codes", metrics.codes.WithLabelValues(endpoint, "-101"), 1},
+		{"refreshes", metrics.refreshes.WithLabelValues("success"), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testutil.ToFloat64(tt.collector); got != tt.want {
+				t.Errorf("ToFloat64() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := testutil.CollectAndCount(metrics.requestDuration); got != 1 {
+		t.Errorf("CollectAndCount(requestDuration) = %v, want 1", got)
+	}
+}
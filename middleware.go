@@ -0,0 +1,60 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/kainhuck/bilibili-go/internal/net"
+	"io"
+	"net/http"
+)
+
+// RoundTripFunc 发送一个请求并返回响应
+type RoundTripFunc = net.RoundTripFunc
+
+// Middleware 请求中间件，所有接口请求（包括视频分片上传）都会经过中间件，
+// 可以用来注入header、记录流量、统计耗时或者直接返回自定义响应。
+// 需要登录的请求在进入中间件之前已经带有 Cookie 头
+//
+//	func(next RoundTripFunc) RoundTripFunc {
+//		return func(request *http.Request) (*http.Response, error) {
+//			start := time.Now()
+//			defer func() { log.Println(request.URL, time.Since(start)) }()
+//
+//			return next(request)
+//		}
+//	}
+type Middleware = net.Middleware
+
+// peekedBody 已读取的响应体，缓存解析出的业务码，
+// 同一个响应经过多个中间件（metrics、tracing、retry、cache、proxy 等）时只解析一次
+type peekedBody struct {
+	*bytes.Reader
+	code *Code
+}
+
+func (*peekedBody) Close() error {
+	return nil
+}
+
+// peekCode 读取响应中的业务码，读取后会重置 resp.Body，响应中没有 code 字段时返回nil
+func peekCode(resp *http.Response) (*Code, error) {
+	if body, ok := resp.Body.(*peekedBody); ok && int64(body.Len()) == body.Size() {
+		return body.code, nil
+	}
+
+	bts, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var codeResp struct {
+		Code *Code `json:"code"`
+	}
+	if json.Unmarshal(bts, &codeResp) != nil {
+		codeResp.Code = nil
+	}
+	resp.Body = &peekedBody{Reader: bytes.NewReader(bts), code: codeResp.Code}
+
+	return codeResp.Code, nil
+}
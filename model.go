@@ -18,6 +18,14 @@ const (
 	CodeUnLogin Code = -101
 	// CodeRequestError 请求错误
 	CodeRequestError Code = -400
+	// CodeAccessDenied 访问权限不足
+	CodeAccessDenied Code = -403
+	// CodeNotFound 资源不存在
+	CodeNotFound Code = -404
+	// CodeRiskControl 请求被风控拦截
+	CodeRiskControl Code = -412
+	// CodeTooManyRequests 请求过于频繁
+	CodeTooManyRequests Code = -799
 	// CodePermissionDenied 没有权限
 	CodePermissionDenied Code = 22104
 	// CodeUnFollowed 未关注
@@ -38,6 +46,54 @@ func (r *BaseResponse) RawData() []byte {
 	return bts
 }
 
+// Response 接口响应，data 直接解析为 T，避免先解析为 interface{} 再重新序列化，同时保留 int64 的精度
+type Response[T any] struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	TTL     int    `json:"ttl"`
+	Data    T      `json:"data"`
+}
+
+// response 与 Response 字段相同但没有 UnmarshalJSON，用于避免递归
+type response[T any] struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	TTL     int    `json:"ttl"`
+	Data    T      `json:"data"`
+}
+
+// rawResponse data 保留为原始json，用于 Call 和 CallInto
+type rawResponse = response[json.RawMessage]
+
+// toBaseResponse 将 rawResponse 转为 BaseResponse
+func toBaseResponse(r *rawResponse) (*BaseResponse, error) {
+	baseResp := &BaseResponse{Code: r.Code, Message: r.Message, TTL: r.TTL}
+	if len(r.Data) > 0 {
+		if err := json.Unmarshal(r.Data, &baseResp.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	return baseResp, nil
+}
+
+// UnmarshalJSON 业务码不为 CodeSuccess 时返回 *APIError，此时 data 的类型可能与成功时不同，不会解析到 Data
+func (r *Response[T]) UnmarshalJSON(bts []byte) error {
+	var resp response[T]
+	err := json.Unmarshal(bts, &resp)
+	if err == nil && resp.Code == CodeSuccess {
+		*r = Response[T](resp)
+		return nil
+	}
+
+	var base BaseResponse
+	if baseErr := json.Unmarshal(bts, &base); baseErr == nil && base.Code != CodeSuccess {
+		return &APIError{BaseResponse: base}
+	}
+
+	return err
+}
+
 func NewBaseResponse(body io.Reader) (*BaseResponse, error) {
 	resp := &BaseResponse{}
 	err := json.NewDecoder(body).Decode(&resp)
@@ -753,6 +809,11 @@ type TripleVideoResponse struct {
 	Multiply int  `json:"multiply"` // 投币数量
 }
 
+// videoList 视频排行榜等接口的响应
+type videoList struct {
+	List []*Video `json:"list"`
+}
+
 type Video struct {
 	Aid         int         `json:"aid"`       // avid
 	Videos      int         `json:"videos"`    // 分P总数
@@ -862,3 +923,84 @@ type GetLatestVideoResponse struct {
 		Size  int `json:"size"`  // 每页项数
 	} `json:"page"`
 }
+
+// ArchiveViewResponse 创作中心的稿件详情
+type ArchiveViewResponse struct {
+	Archive struct {
+		Aid          int64        `json:"aid"`
+		Bvid         string       `json:"bvid"`
+		State        ArchiveState `json:"state"`
+		StateDesc    string       `json:"state_desc"`
+		RejectReason string       `json:"reject_reason"`
+		SubmitRequest
+	} `json:"archive"`
+	Videos []struct {
+		Aid          int64  `json:"aid"`
+		CID          int    `json:"cid"`
+		Index        int    `json:"index"` // 分P序号，从1开始
+		Title        string `json:"title"`
+		Desc         string `json:"desc"`
+		Filename     string `json:"filename"`
+		Status       int    `json:"status"`
+		RejectReason string `json:"reject_reason"`
+	} `json:"videos"`
+}
+
+// CreatorArchive 创作中心的稿件
+type CreatorArchive struct {
+	Aid          int64        `json:"aid"`
+	Bvid         string       `json:"bvid"`
+	Title        string       `json:"title"`
+	Cover        string       `json:"cover"`
+	Desc         string       `json:"desc"`
+	Tag          string       `json:"tag"`
+	TID          int          `json:"tid"`
+	TypeName     string       `json:"typename"`
+	Copyright    int          `json:"copyright"`
+	Duration     int64        `json:"duration"`
+	State        ArchiveState `json:"state"`
+	StateDesc    string       `json:"state_desc"`    // 状态说明
+	RejectReason string       `json:"reject_reason"` // 退回原因
+	Ptime        int64        `json:"ptime"`         // 发布时间
+	Ctime        int64        `json:"ctime"`         // 投稿时间
+}
+
+// CreatorArchiveVideo 稿件的分P
+type CreatorArchiveVideo struct {
+	CID          int    `json:"cid"`
+	Index        int    `json:"index"` // 分P序号，从1开始
+	Title        string `json:"title"`
+	Filename     string `json:"filename"`
+	Status       int    `json:"status"`
+	RejectReason string `json:"reject_reason"` // 退回原因
+}
+
+// CreatorArchiveItem 创作中心稿件列表中的一项
+type CreatorArchiveItem struct {
+	Archive CreatorArchive         `json:"Archive"`
+	Videos  []*CreatorArchiveVideo `json:"Videos"`
+	Stat    struct {
+		View     int64 `json:"view"`
+		Danmaku  int64 `json:"danmaku"`
+		Reply    int64 `json:"reply"`
+		Favorite int64 `json:"favorite"`
+		Coin     int64 `json:"coin"`
+		Share    int64 `json:"share"`
+		Like     int64 `json:"like"`
+	} `json:"stat"`
+}
+
+// GetCreatorArchivesResponse 创作中心稿件列表
+type GetCreatorArchivesResponse struct {
+	Class struct {
+		Pubed    int `json:"pubed"`     // 已通过
+		NotPubed int `json:"not_pubed"` // 未通过
+		IsPubing int `json:"is_pubing"` // 进行中
+	} `json:"class"`
+	ArcAudits []*CreatorArchiveItem `json:"arc_audits"`
+	Page      struct {
+		Pn    int `json:"pn"`
+		Ps    int `json:"ps"`
+		Count int `json:"count"`
+	} `json:"page"`
+}
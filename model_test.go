@@ -0,0 +1,93 @@
+package bilibili_go
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// followersPayload 模拟一页粉丝列表
+func followersPayload(n int) []byte {
+	users := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		users = append(users, fmt.Sprintf(`{"mid":%d,"attribute":0,"mtime":1700000000,"tag":null,"special":0,`+
+			`"uname":"user%d","face":"https://i0.hdslb.com/bfs/face/%d.jpg","sign":"签名签名签名签名签名签名",`+
+			`"official_verify":{"type":-1,"desc":""},"vip":{"vipType":1,"vipDueDate":1700000000000,"vipStatus":1}}`,
+			9007199254740993+int64(i), i, i))
+	}
+
+	return []byte(`{"code":0,"message":"0","ttl":1,"data":{"list":[` + strings.Join(users, ",") + `],"re_version":0,"total":` + fmt.Sprint(n) + `}}`)
+}
+
+func TestResponse(t *testing.T) {
+	var resp Response[RelationUserResponse]
+	if err := json.Unmarshal(followersPayload(1), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Data.List[0].Mid; got != 9007199254740993 {
+		t.Errorf("mid = %d, want 9007199254740993 without float64 precision loss", got)
+	}
+
+	err := json.Unmarshal([]byte(`{"code":-404,"message":"啥都木有","ttl":1,"data":[]}`), &resp)
+	if !IsCode(err, -404) {
+		t.Fatalf("err = %v, want APIError with code -404", err)
+	}
+	if err.Error() != `{"code":-404,"message":"啥都木有","ttl":1,"data":[]}` {
+		t.Errorf("err = %s", err)
+	}
+}
+
+func TestBaseResponse(t *testing.T) {
+	resp, err := NewBaseResponse(strings.NewReader(`{"code":0,"message":"0","ttl":1,"data":{"mid":2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Data 仍然解析为 interface{}，兼容直接断言 map 的调用方
+	data, ok := resp.Data.(map[string]interface{})
+	if !ok || data["mid"] != float64(2) {
+		t.Fatalf("data = %#v", resp.Data)
+	}
+	if string(resp.RawData()) != `{"mid":2}` {
+		t.Errorf("raw data = %s", resp.RawData())
+	}
+}
+
+// legacyResponse 之前的解析方式，data 先解析为 interface{}
+type legacyResponse struct {
+	Code    Code        `json:"code"`
+	Message string      `json:"message"`
+	TTL     int         `json:"ttl"`
+	Data    interface{} `json:"data"`
+}
+
+func BenchmarkDecodeLegacy(b *testing.B) {
+	payload := followersPayload(50)
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var base legacyResponse
+		if err := json.Unmarshal(payload, &base); err != nil {
+			b.Fatal(err)
+		}
+		raw, _ := json.Marshal(base.Data)
+		rsp := &RelationUserResponse{}
+		if err := json.Unmarshal(raw, &rsp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeResponse(b *testing.B) {
+	payload := followersPayload(50)
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		var resp Response[RelationUserResponse]
+		if err := json.Unmarshal(payload, &resp); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"github.com/skip2/go-qrcode"
+	"io"
 	"net/http"
 	"os"
 	"time"
@@ -13,17 +14,23 @@ type options struct {
 	// UserAgent 自定义用户头
 	UserAgent string
 
-	// HttpClient 自定义http客户端
+	// HttpClient 自定义http客户端，需要携带cookie的请求会使用其副本并替换 Jar
 	HttpClient *http.Client
 
 	// AuthStorage 认证信息存储
 	AuthStorage AuthStorage
 
-	// Debug 是否开启调试模式，如果开启则会将http的请求信息输出到output，如果output为nil则视为os.Stdout
+	// Debug 是否开启调试模式，如果开启则会将http的请求信息以HAR格式输出到output，如果output为nil则视为os.Stdout
 	Debug *debugInfo
 
+	// DebugOptions 调试模式下HAR记录的配置，默认对认证信息脱敏并将二进制报文截断为1KB
+	DebugOptions HarOptions
+
 	// Logger 自定义日志
-	Logger Logger
+	Logger StructuredLogger
+
+	// LogLevels 各子系统的日志等级，默认为 LevelInfo
+	LogLevels map[Subsystem]LogLevel
 
 	// ShowQRCodeFunc 自定义输出二维码的方法，默认在stdout输出，
 	// 可以通过自定义该方法可以实现其他输出，比如将图片发送到消息通知群
@@ -31,6 +38,48 @@ type options struct {
 
 	// RefreshInterval cookie 刷新间隔单位秒 默认60s 设置为0则关闭定时刷新
 	RefreshInterval time.Duration
+
+	// Middlewares 请求中间件，先添加的位于外层
+	Middlewares []Middleware
+
+	// Recorder 录制回放，为nil则不开启
+	Recorder *recorderOption
+
+	// Metrics 指标上报
+	Metrics Metrics
+
+	// Tracer 链路追踪
+	Tracer Tracer
+
+	// Cache 只读接口的响应缓存，为nil则不开启
+	Cache *responseCache
+
+	// ProxyPool 代理池，为nil则不开启
+	ProxyPool *ProxyPool
+
+	// HeaderProfiles 各接口族的默认请求头，会覆盖 DefaultHeaderProfiles 中相同前缀的配置
+	HeaderProfiles map[string]HeaderProfile
+
+	// UserAgents User-Agent 池，不为空时每个 Client 随机固定选择其中一个，覆盖 UserAgent
+	UserAgents []string
+
+	// RetryPolicy 重试策略，默认不重试
+	RetryPolicy RetryPolicy
+
+	// RateLimiter 请求限流，为nil则不限制
+	RateLimiter *limiter
+
+	// UploadSessionStore 上传会话存储
+	UploadSessionStore UploadSessionStore
+
+	// UploadBandwidth 所有上传的总带宽限制，字节/秒，不大于0时不限制
+	UploadBandwidth int64
+
+	// UploadLines 默认的上传线路，默认为 DefaultUploadLines
+	UploadLines []UploadLine
+
+	// ProbeUploadLines 上传前是否对候选线路测速
+	ProbeUploadLines bool
 }
 
 type Option interface {
@@ -79,7 +128,7 @@ func (d debug) apply(opt *options) {
 	opt.Debug = d.debugInfo
 }
 
-func WithDebug(d bool, output ...*os.File) Option {
+func WithDebug(d bool, output ...io.Writer) Option {
 	info := &debugInfo{
 		debug: d,
 	}
@@ -90,18 +139,63 @@ func WithDebug(d bool, output ...*os.File) Option {
 	return debug{info}
 }
 
+type debugOptions HarOptions
+
+func (d debugOptions) apply(opt *options) {
+	opt.DebugOptions = HarOptions(d)
+}
+
+// WithDebugOptions 设置调试模式下HAR记录的配置
+func WithDebugOptions(harOptions HarOptions) Option {
+	return debugOptions(harOptions)
+}
+
 type log struct {
-	logger Logger
+	logger StructuredLogger
 }
 
 func (l log) apply(opt *options) {
 	opt.Logger = l.logger
 }
 
+// WithLogger 使用 printf 风格的日志，日志字段会以 key=value 的形式追加到消息后，logrus 会直接使用字段输出
 func WithLogger(logger Logger) Option {
+	return log{logger: toStructuredLogger(logger)}
+}
+
+// WithStructuredLogger 使用结构化日志，可以使用 NewSlogLogger 或 NewLogrusLogger 适配
+func WithStructuredLogger(logger StructuredLogger) Option {
 	return log{logger: logger}
 }
 
+type logLevel struct {
+	level      LogLevel
+	subsystems []Subsystem
+}
+
+func (l logLevel) apply(opt *options) {
+	levels := make(map[Subsystem]LogLevel, len(opt.LogLevels)+len(l.subsystems))
+	for subsystem, level := range opt.LogLevels {
+		levels[subsystem] = level
+	}
+
+	subsystems := l.subsystems
+	if len(subsystems) == 0 {
+		subsystems = []Subsystem{SubsystemAuth, SubsystemUpload, SubsystemHTTP}
+	}
+	for _, subsystem := range subsystems {
+		levels[subsystem] = l.level
+	}
+	opt.LogLevels = levels
+}
+
+// WithLogLevel 设置子系统的日志等级，不指定子系统则设置所有子系统
+//
+//	bilibili_go.WithLogLevel(bilibili_go.LevelDebug, bilibili_go.SubsystemHTTP)
+func WithLogLevel(level LogLevel, subsystems ...Subsystem) Option {
+	return logLevel{level: level, subsystems: subsystems}
+}
+
 type showQRCodeFunc func(code *qrcode.QRCode) error
 
 func (s showQRCodeFunc) apply(opt *options) {
@@ -122,26 +216,231 @@ func WithRefreshInterval(interval time.Duration) Option {
 	return refreshInterval(interval)
 }
 
+type middlewares []Middleware
+
+func (m middlewares) apply(opt *options) {
+	opt.Middlewares = append(opt.Middlewares, m...)
+}
+
+// WithMiddleware 添加请求中间件，可多次调用，先添加的位于外层
+func WithMiddleware(m ...Middleware) Option {
+	return middlewares(m)
+}
+
+type recorderOption struct {
+	path string
+	mode RecorderMode
+}
+
+func (r recorderOption) apply(opt *options) {
+	opt.Recorder = &r
+}
+
+// WithRecorder 开启录制回放，RecordMode 下会将所有请求和响应完整地保存到 path（不脱敏，文件中包含 cookie 等认证信息），
+// ReplayMode 下不再访问网络，而是从 path 中按 method、path、查询参数（忽略 wts、w_rid、t）以及表单或json请求体匹配返回录制的响应；
+// 文件在 NewClient 时打开
+func WithRecorder(path string, mode RecorderMode) Option {
+	return recorderOption{path: path, mode: mode}
+}
+
+type metrics struct {
+	metrics Metrics
+}
+
+func (m metrics) apply(opt *options) {
+	if m.metrics == nil {
+		opt.Metrics = nopMetrics{}
+		return
+	}
+	opt.Metrics = m.metrics
+}
+
+// WithMetrics 设置指标上报，nil 表示不上报
+func WithMetrics(m Metrics) Option {
+	return metrics{metrics: m}
+}
+
+type tracer struct {
+	tracer Tracer
+}
+
+func (t tracer) apply(opt *options) {
+	if t.tracer == nil {
+		opt.Tracer = nopTracer{}
+		return
+	}
+	opt.Tracer = t.tracer
+}
+
+// WithTracer 设置链路追踪，UploadVideo、SubmitVideo、RefreshAuthInfo 以及其中的每个http请求都会创建 span，nil 表示不追踪
+func WithTracer(t Tracer) Option {
+	return tracer{tracer: t}
+}
+
+type cacheOption struct {
+	cache Cache
+	ttls  map[string]time.Duration
+}
+
+func (c cacheOption) apply(opt *options) {
+	opt.Cache = &responseCache{cache: c.cache, ttls: c.ttls}
+}
+
+// WithCache 开启只读接口的响应缓存，ttls 为各接口（见 Endpoint）的缓存时间，为nil则使用 DefaultCacheTTLs，
+// 缓存key 忽略 wbi 签名参数并区分账号，关注等写操作成功后会使相关接口的缓存失效
+func WithCache(cache Cache, ttls map[string]time.Duration) Option {
+	if ttls == nil {
+		ttls = DefaultCacheTTLs
+	}
+
+	return cacheOption{cache: cache, ttls: ttls}
+}
+
+type proxyPool struct {
+	pool *ProxyPool
+}
+
+func (p proxyPool) apply(opt *options) {
+	opt.ProxyPool = p.pool
+}
+
+// WithProxyPool 使用代理池，每个请求按策略选择代理，会覆盖 HttpClient 中 Transport 的 Proxy，
+// HttpClient 的 Transport 必须为nil或 *http.Transport，否则会记录错误日志并且不使用代理池
+func WithProxyPool(pool *ProxyPool) Option {
+	return proxyPool{pool: pool}
+}
+
+type headerProfiles map[string]HeaderProfile
+
+func (h headerProfiles) apply(opt *options) {
+	if opt.HeaderProfiles == nil {
+		opt.HeaderProfiles = make(map[string]HeaderProfile)
+	}
+	for prefix, profile := range h {
+		opt.HeaderProfiles[prefix] = profile
+	}
+}
+
+// WithHeaderProfiles 设置接口族的默认请求头，key 为接口名称（见 Endpoint）的前缀，
+// 会覆盖 DefaultHeaderProfiles 中相同前缀的配置，设置为空的 HeaderProfile 可以关闭该前缀的默认请求头
+func WithHeaderProfiles(profiles map[string]HeaderProfile) Option {
+	return headerProfiles(profiles)
+}
+
+type userAgents []string
+
+func (u userAgents) apply(opt *options) {
+	opt.UserAgents = u
+}
+
+// WithUserAgentPool 设置 User-Agent 池，创建 Client 时随机选择一个，之后登录前后的所有请求都使用同一个 User-Agent
+func WithUserAgentPool(uas ...string) Option {
+	return userAgents(uas)
+}
+
+type retryPolicy RetryPolicy
+
+func (r retryPolicy) apply(opt *options) {
+	opt.RetryPolicy = RetryPolicy(r)
+}
+
+// WithRetry 设置重试策略，网络错误、429、5xx 以及指定的业务码会按指数退避重试，默认只重试 GET、HEAD 请求，见 RetryPolicy
+func WithRetry(policy RetryPolicy) Option {
+	if policy.Backoff <= 0 {
+		policy.Backoff = 500 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 10 * time.Second
+	}
+	if policy.Codes == nil {
+		policy.Codes = []Code{CodeTooManyRequests}
+	}
+
+	return retryPolicy(policy)
+}
+
+type rateLimit struct {
+	rate  float64
+	burst int
+}
+
+func (r rateLimit) apply(opt *options) {
+	opt.RateLimiter = newLimiter(r.rate, r.burst)
+}
+
+// WithRateLimit 限制每秒请求数，burst 为允许的突发请求数，重试的请求同样受限制，视频分片上传不受限制
+func WithRateLimit(rps float64, burst int) Option {
+	return rateLimit{rate: rps, burst: burst}
+}
+
+type uploadSessionStore struct {
+	store UploadSessionStore
+}
+
+func (u uploadSessionStore) apply(opt *options) {
+	opt.UploadSessionStore = u.store
+}
+
+// WithUploadSessionStore 保存视频上传会话，上传中断后可以通过 ResumeUpload 继续上传
+func WithUploadSessionStore(store UploadSessionStore) Option {
+	return uploadSessionStore{store: store}
+}
+
+type uploadBandwidth int64
+
+func (u uploadBandwidth) apply(opt *options) {
+	opt.UploadBandwidth = int64(u)
+}
+
+// WithUploadBandwidth 限制 Client 所有视频上传的总带宽，字节/秒，可以通过 SetUploadBandwidth 修改
+func WithUploadBandwidth(bytesPerSecond int64) Option {
+	return uploadBandwidth(bytesPerSecond)
+}
+
+type defaultUploadLines []UploadLine
+
+func (d defaultUploadLines) apply(opt *options) {
+	opt.UploadLines = d
+}
+
+// WithDefaultUploadLines 设置默认的上传线路，按顺序尝试，可以被 WithUploadLine 覆盖
+func WithDefaultUploadLines(lines ...UploadLine) Option {
+	return defaultUploadLines(lines)
+}
+
+type defaultLineProbe bool
+
+func (d defaultLineProbe) apply(opt *options) {
+	opt.ProbeUploadLines = bool(d)
+}
+
+// WithDefaultLineProbe 设置上传前是否默认对候选线路测速，可以被 WithLineProbe 覆盖
+func WithDefaultLineProbe(probe bool) Option {
+	return defaultLineProbe(probe)
+}
+
 /* ========================================================== */
 
 var defaultOptions = options{
 	UserAgent:  "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/70.0.3538.77 Safari/537.36",
 	HttpClient: http.DefaultClient,
 	Debug:      &debugInfo{},
-	Logger:     logrus.StandardLogger(),
+	Logger:     NewLogrusLogger(logrus.StandardLogger()),
 	ShowQRCodeFunc: func(code *qrcode.QRCode) error {
 		_, err := fmt.Fprint(os.Stdout, code.ToSmallString(true))
 
 		return err
 	},
 	RefreshInterval: time.Minute,
+	Metrics:         nopMetrics{},
+	Tracer:          nopTracer{},
 }
 
 func applyOptions(opts ...Option) *options {
-	opt := &defaultOptions
+	opt := defaultOptions
 	for _, o := range opts {
-		o.apply(opt)
+		o.apply(&opt)
 	}
 
-	return opt
+	return &opt
 }
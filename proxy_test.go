@@ -0,0 +1,141 @@
+package bilibili_go
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// newTestProxy 模拟代理，直接返回 code，响应头 X-Proxy 为代理名称
+func newTestProxy(t *testing.T, name string, code *string) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proxy", name)
+		_, _ = w.Write([]byte(`{"code":` + *code + `}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func TestProxyPool(t *testing.T) {
+	goodCode, badCode := "0", "0"
+	good := newTestProxy(t, "good", &goodCode)
+	bad := newTestProxy(t, "bad", &badCode)
+
+	tests := []struct {
+		name     string
+		strategy ProxyStrategy
+		want     []string
+	}{
+		{name: "round robin", strategy: ProxyRoundRobin, want: []string{"good", "bad", "good", "bad"}},
+		{name: "sticky", strategy: ProxySticky, want: []string{"good", "good", "good", "good"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, err := NewProxyPool([]string{good, bad}, ProxyPoolOptions{Strategy: tt.strategy, HealthCheckInterval: -1})
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := NewClient(WithHttpClient(&http.Client{}), WithRefreshInterval(0), WithProxyPool(pool))
+
+			for i, want := range tt.want {
+				resp, _, err := client.getHttpClient(false).Get("http://api.bilibili.com/x/web-interface/nav").End()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := resp.Header.Get("X-Proxy"); got != want {
+					t.Errorf("request %d used %s, want %s", i, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("evict and recover", func(t *testing.T) {
+		pool, err := NewProxyPool([]string{good, bad}, ProxyPoolOptions{HealthCheckInterval: -1, HealthCheckURL: "http://api.bilibili.com/x/web-interface/nav"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := NewClient(WithHttpClient(&http.Client{}), WithRefreshInterval(0), WithProxyPool(pool))
+
+		badCode = "-412"
+		defer func() { badCode = "0" }()
+		for i := 0; i < 4; i++ {
+			if _, _, err := client.getHttpClient(false).Get("http://api.bilibili.com/x/web-interface/nav").End(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if available := pool.Available(); len(available) != 1 || available[0].String() != good {
+			t.Fatalf("available = %v, want only %s", available, good)
+		}
+
+		badCode = "0"
+		pool.CheckHealth(context.Background())
+		if available := pool.Available(); len(available) != 2 {
+			t.Fatalf("available = %v, want both proxies after health check", available)
+		}
+	})
+}
+
+func TestProxyPoolReport(t *testing.T) {
+	pool, err := NewProxyPool([]string{"http://127.0.0.1:8080"}, ProxyPoolOptions{MaxFailures: 2, HealthCheckInterval: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy := pool.proxies[0]
+
+	failed := errors.New("connection refused")
+	if reason := pool.report(proxy, failed, nil); reason != "" {
+		t.Errorf("first failure evicted: %s", reason)
+	}
+	if reason := pool.report(proxy, failed, nil); reason != "too many failures" {
+		t.Errorf("reason = %q, want too many failures", reason)
+	}
+	if proxy.totalFailures != 2 {
+		t.Errorf("totalFailures = %d, want 2", proxy.totalFailures)
+	}
+
+	if reason := pool.report(proxy, context.Canceled, nil); reason != "" || proxy.totalFailures != 2 {
+		t.Errorf("canceled request counted as failure, reason = %q, totalFailures = %d", reason, proxy.totalFailures)
+	}
+}
+
+func TestProxyPoolPickSkipsEvicted(t *testing.T) {
+	pool, err := NewProxyPool([]string{"http://a:8080", "http://b:8080", "http://c:8080"}, ProxyPoolOptions{HealthCheckInterval: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.evict(pool.proxies[1])
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		proxy, err := pool.pick(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, proxy.url.Host)
+	}
+	if want := []string{"a:8080", "c:8080", "a:8080", "c:8080"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("picked %v, want %v", got, want)
+	}
+}
+
+func TestProxyPoolCustomTransport(t *testing.T) {
+	pool, err := NewProxyPool([]string{"http://127.0.0.1:8080"}, ProxyPoolOptions{HealthCheckInterval: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &memoryLogger{}
+	client := newTestClient(t, http.NotFoundHandler(), WithProxyPool(pool), WithStructuredLogger(logger))
+	if client.proxyPool != nil {
+		t.Error("proxy pool should be disabled with custom RoundTripper")
+	}
+	if msgs := logger.messages(); len(msgs) != 1 || msgs[0] != "proxy pool disabled" {
+		t.Errorf("logged = %v", msgs)
+	}
+}
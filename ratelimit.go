@@ -0,0 +1,92 @@
+package bilibili_go
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// limiter 令牌桶限流，rate 为每秒产生的令牌数，不大于0时不限制
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &limiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// SetRate 修改速率，正在等待的请求不受影响
+func (l *limiter) SetRate(rate float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(time.Now())
+	l.rate = rate
+}
+
+// WaitN 等待 n 个令牌，n 可以大于 burst
+func (l *limiter) WaitN(ctx context.Context, n int) error {
+	l.mu.Lock()
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	l.advance(time.Now())
+	l.tokens -= float64(n)
+	var wait time.Duration
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens += float64(n)
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// advance 补充令牌，需持有 mu
+func (l *limiter) advance(now time.Time) {
+	if l.rate > 0 {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+}
+
+// rateLimitMiddleware 限制接口请求频率，视频分片上传不受限制
+func (c *Client) rateLimitMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			if Endpoint(request.URL) != "upos" {
+				if err := c.rateLimiter.WaitN(request.Context(), 1); err != nil {
+					return nil, err
+				}
+			}
+
+			return next(request)
+		}
+	}
+}
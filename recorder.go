@@ -0,0 +1,327 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RecorderMode 录制回放模式
+type RecorderMode int
+
+const (
+	// RecordMode 录制模式，将所有请求和响应以 HAR 格式完整地保存到文件，不脱敏也不截断
+	RecordMode RecorderMode = iota + 1
+	// ReplayMode 回放模式，不发送网络请求，从文件中读取录制的响应
+	ReplayMode
+)
+
+// ErrRecordNotFound 回放模式下没有找到匹配的录制记录
+var ErrRecordNotFound = errors.New("recorded response not found")
+
+// 匹配请求时忽略的易变参数
+var recorderVolatileParams = map[string]bool{
+	"wts":   true,
+	"w_rid": true,
+	"t":     true,
+}
+
+// recorder 录制或回放请求，录制文件为 HAR 格式，可以使用 LoadHarFile 查看
+type recorder struct {
+	mu      sync.Mutex
+	mode    RecorderMode
+	file    *os.File
+	writer  *HarWriter // 录制模式下流式写入 file，进程异常退出时文件也可以通过 LoadHarFile 加载
+	loadErr error      // 回放文件加载失败或者录制文件创建失败
+	cursors map[string]int
+	index   map[string][]*HarEntry
+}
+
+func newRecorder(path string, mode RecorderMode) *recorder {
+	r := &recorder{
+		mode:    mode,
+		cursors: make(map[string]int),
+		index:   make(map[string][]*HarEntry),
+	}
+
+	if mode == RecordMode {
+		r.file, r.loadErr = os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if r.loadErr == nil {
+			// 回放需要原样的响应，录制时不脱敏也不截断二进制报文
+			r.writer = NewHarWriter(r.file, HarOptions{DisableRedaction: true, MaxBodySize: math.MaxInt})
+		}
+		return r
+	}
+
+	har, err := LoadHarFile(path)
+	if err != nil {
+		r.loadErr = err
+		return r
+	}
+	for _, entry := range har.Log.Entries {
+		var mimeType, body string
+		if entry.Request.PostData != nil {
+			mimeType, body = entry.Request.PostData.MimeType, entry.Request.PostData.Text
+		}
+		key, err := recordKey(entry.Request.Method, entry.Request.URL, mimeType, body)
+		if err != nil {
+			continue
+		}
+		r.index[key] = append(r.index[key], entry)
+	}
+
+	return r
+}
+
+func (r *recorder) middleware() Middleware {
+	switch {
+	case r.loadErr != nil:
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(*http.Request) (*http.Response, error) {
+				return nil, r.loadErr
+			}
+		}
+	case r.mode == ReplayMode:
+		return func(next RoundTripFunc) RoundTripFunc {
+			return r.replay
+		}
+	}
+
+	return r.writer.Middleware()
+}
+
+// onError 设置录制文件写入失败时的回调
+func (r *recorder) onError(f func(error)) {
+	if r.writer != nil {
+		r.writer.onError = f
+	}
+}
+
+// close 补全录制文件结尾并关闭文件，返回录制过程中第一次写入失败的错误
+func (r *recorder) close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.writer.Close()
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	r.file = nil
+
+	return err
+}
+
+// replay 按照 method、path 以及规范化后的查询参数和请求体匹配录制的响应，
+// 相同请求按录制顺序依次返回，用完后一直返回最后一个
+func (r *recorder) replay(request *http.Request) (*http.Response, error) {
+	if r.loadErr != nil {
+		return nil, r.loadErr
+	}
+
+	// 与 HAR 记录 postData 的条件一致
+	var mimeType, body string
+	if request.Body != nil && request.GetBody != nil {
+		mimeType = request.Header.Get("Content-Type")
+		reader, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		bts, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = string(bts)
+	}
+
+	key, err := recordKey(request.Method, request.URL.String(), mimeType, body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	entries := r.index[key]
+	if len(entries) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrRecordNotFound, key)
+	}
+	cursor := r.cursors[key]
+	if cursor < len(entries)-1 {
+		r.cursors[key] = cursor + 1
+	}
+	entry := entries[cursor]
+	r.mu.Unlock()
+
+	if request.Body != nil {
+		_ = request.Body.Close()
+	}
+
+	return harToResponse(request, entry.Response)
+}
+
+// recordKey 生成请求的匹配键，查询参数按key排序，忽略易变参数，脱敏参数只保留key；
+// GET 以外的请求追加表单或json请求体规范化后的摘要
+func recordKey(method string, rawURL string, mimeType string, body string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	key := method + " " + u.Host + u.Path + "?" + normalizeQuery(u.Query(), recorderVolatileParams)
+	if method != http.MethodGet && method != http.MethodHead {
+		if digest := recordBodyDigest(mimeType, body); digest != "" {
+			key += " " + digest
+		}
+	}
+
+	return key, nil
+}
+
+// recordBodyDigest 表单和json请求体规范化后的摘要，其他请求体（比如视频分片）不参与匹配
+func recordBodyDigest(mimeType string, body string) string {
+	if body == "" {
+		return ""
+	}
+
+	var normalized string
+	switch mimeType = strings.ToLower(mimeType); {
+	case strings.HasPrefix(mimeType, "application/x-www-form-urlencoded"):
+		form, err := url.ParseQuery(body)
+		if err != nil {
+			return ""
+		}
+		normalized = normalizeQuery(form, recorderVolatileParams)
+	case strings.HasPrefix(mimeType, "application/json"):
+		normalized = normalizeJSON(body)
+	default:
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+// normalizeJSON 重新编码json使key有序，忽略顶层的易变参数，顶层脱敏参数的值统一替换
+func normalizeJSON(body string) string {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+
+	if object, ok := value.(map[string]interface{}); ok {
+		for key := range recorderVolatileParams {
+			delete(object, key)
+		}
+		for _, secret := range harSecretParams {
+			if _, ok := object[secret].(string); ok {
+				object[secret] = harRedacted
+			}
+		}
+	}
+
+	bts, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+
+	return string(bts)
+}
+
+// normalizeQuery 将查询参数按key排序后编码，忽略 ignore 中的参数，脱敏参数的值统一替换
+func normalizeQuery(query url.Values, ignore map[string]bool) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if !ignore[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	for _, key := range keys {
+		values := query[key]
+		for _, secret := range harSecretParams {
+			if key == secret {
+				values = []string{harRedacted}
+			}
+		}
+		for _, value := range values {
+			if buf.Len() > 0 {
+				buf.WriteByte('&')
+			}
+			buf.WriteString(url.QueryEscape(key))
+			buf.WriteByte('=')
+			buf.WriteString(url.QueryEscape(value))
+		}
+	}
+
+	return buf.String()
+}
+
+func harToResponse(request *http.Request, harResponse *HarResponse) (*http.Response, error) {
+	var body []byte
+	if harResponse.Content != nil {
+		body = []byte(harResponse.Content.Text)
+		if harResponse.Content.Encoding == "base64" {
+			var err error
+			body, err = base64.StdEncoding.DecodeString(harResponse.Content.Text)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	header := make(http.Header)
+	for _, each := range harResponse.Headers {
+		if http.CanonicalHeaderKey(each.Name) == "Set-Cookie" {
+			continue
+		}
+		header.Add(each.Name, each.Value)
+	}
+	// Set-Cookie 的 header 已经整体脱敏，根据 cookies 重新生成
+	for _, each := range harResponse.Cookies {
+		cookie := &http.Cookie{
+			Name:     each.Name,
+			Value:    each.Value,
+			Path:     each.Path,
+			Domain:   each.Domain,
+			HttpOnly: each.HTTPOnly,
+			Secure:   each.Secure,
+		}
+		if each.Expires != nil {
+			cookie.Expires = *each.Expires
+		}
+		header.Add("Set-Cookie", cookie.String())
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", harResponse.Status, harResponse.StatusText),
+		StatusCode:    harResponse.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}, nil
+}
@@ -0,0 +1,172 @@
+package bilibili_go
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.har")
+
+	// 文件在 NewClient 时才打开
+	WithRecorder(path, RecordMode).apply(&options{})
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("WithRecorder() should not open the file, stat error = %v", err)
+	}
+
+	calls := 0
+	client := newTestClient(t, testAPIHandler(&calls), WithRecorder(path, RecordMode))
+	recorded, err := client.GetUserInfo(2)
+	if err != nil {
+		t.Fatalf("record GetUserInfo() error = %v", err)
+	}
+	if calls == 0 {
+		t.Fatalf("record mode should send requests")
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if har, err := LoadHarFile(path); err != nil || len(har.Log.Entries) != calls {
+		t.Fatalf("recorded har = %v, %v, want %d entries", har, err, calls)
+	}
+
+	calls = 0
+	client = newTestClient(t, testAPIHandler(&calls), WithRecorder(path, ReplayMode))
+	replayed, err := client.GetUserInfo(2)
+	if err != nil {
+		t.Fatalf("replay GetUserInfo() error = %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("replay mode sent %v requests", calls)
+	}
+	if replayed.Mid != recorded.Mid || replayed.Name != recorded.Name {
+		t.Errorf("replay GetUserInfo() = %+v, want %+v", replayed, recorded)
+	}
+
+	if _, err := client.GetUserInfo(3); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("replay GetUserInfo() with unknown mid error = %v, want %v", err, ErrRecordNotFound)
+	}
+}
+
+func TestRecordKey(t *testing.T) {
+	const (
+		form     = "application/x-www-form-urlencoded"
+		jsonType = "application/json;charset=UTF-8"
+	)
+	tests := []struct {
+		name     string
+		method   string
+		a        string
+		b        string
+		mimeType string
+		aBody    string
+		bBody    string
+		same     bool
+	}{
+		{
+			name: "ignore volatile params",
+			a:    "https://api.bilibili.com/x/space/wbi/acc/info?mid=1&wts=1&w_rid=a",
+			b:    "https://api.bilibili.com/x/space/wbi/acc/info?w_rid=b&wts=2&mid=1",
+			same: true,
+		},
+		{
+			name: "ignore secret values",
+			a:    "https://api.bilibili.com/x/relation/modify?csrf=a&t=1",
+			b:    "https://api.bilibili.com/x/relation/modify?csrf=b&t=2",
+			same: true,
+		},
+		{
+			name: "different params",
+			a:    "https://api.bilibili.com/x/space/wbi/acc/info?mid=1",
+			b:    "https://api.bilibili.com/x/space/wbi/acc/info?mid=2",
+		},
+		{
+			name:     "same form in different order",
+			method:   http.MethodPost,
+			a:        "https://api.bilibili.com/x/relation/modify",
+			b:        "https://api.bilibili.com/x/relation/modify",
+			mimeType: form,
+			aBody:    "fid=1&act=1&csrf=a",
+			bBody:    "csrf=b&act=1&fid=1",
+			same:     true,
+		},
+		{
+			name:     "different form",
+			method:   http.MethodPost,
+			a:        "https://api.bilibili.com/x/relation/modify",
+			b:        "https://api.bilibili.com/x/relation/modify",
+			mimeType: form,
+			aBody:    "fid=1&act=1",
+			bBody:    "fid=1&act=2",
+		},
+		{
+			name:     "same json",
+			method:   http.MethodPost,
+			a:        "https://member.bilibili.com/x/vu/web/add/v3",
+			b:        "https://member.bilibili.com/x/vu/web/add/v3",
+			mimeType: jsonType,
+			aBody:    `{"title":"a","tid":1,"csrf":"a"}`,
+			bBody:    `{"csrf":"b", "tid":1, "title":"a"}`,
+			same:     true,
+		},
+		{
+			name:     "different json",
+			method:   http.MethodPost,
+			a:        "https://member.bilibili.com/x/vu/web/add/v3",
+			b:        "https://member.bilibili.com/x/vu/web/add/v3",
+			mimeType: jsonType,
+			aBody:    `{"title":"a"}`,
+			bBody:    `{"title":"b"}`,
+		},
+		{
+			name:     "ignore binary body",
+			method:   http.MethodPut,
+			a:        "https://upos-cs-upcdnbda2.bilivideo.com/ugcfx2lf/n.mp4?partNumber=1",
+			b:        "https://upos-cs-upcdnbda2.bilivideo.com/ugcfx2lf/n.mp4?partNumber=1",
+			mimeType: "application/octet-stream",
+			aBody:    "a",
+			bBody:    "b",
+			same:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			a, _ := recordKey(method, tt.a, tt.mimeType, tt.aBody)
+			b, _ := recordKey(method, tt.b, tt.mimeType, tt.bBody)
+			if (a == b) != tt.same {
+				t.Errorf("recordKey() a = %v, b = %v, same %v", a, b, tt.same)
+			}
+		})
+	}
+}
+
+func TestRecorderWriteError(t *testing.T) {
+	// 录制文件无法创建时请求失败
+	calls := 0
+	client := newTestClient(t, testAPIHandler(&calls), WithRecorder(filepath.Join(t.TempDir(), "missing", "cassette.har"), RecordMode))
+	if _, err := client.GetUserInfo(2); err == nil {
+		t.Error("GetUserInfo() should fail when recorder file can't be created")
+	}
+
+	// 写入失败时记录错误，Close 返回该错误
+	logger := &memoryLogger{}
+	client = newTestClient(t, testAPIHandler(&calls), WithRecorder(filepath.Join(t.TempDir(), "cassette.har"), RecordMode),
+		WithStructuredLogger(logger), WithLogLevel(LevelError))
+	_ = client.recorder.file.Close()
+	if _, err := client.GetUserInfo(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Close(); err == nil {
+		t.Error("Close() should return write error")
+	}
+	if msgs := logger.messages(); len(msgs) != 1 || msgs[0] != "write recorder file failed" {
+		t.Errorf("logged = %v", msgs)
+	}
+}
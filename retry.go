@@ -0,0 +1,132 @@
+package bilibili_go
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy 重试策略，网络错误、429、5xx 以及 Codes 中的业务码会重试。
+// 只重试 GET、HEAD 请求，关注、投币、编辑稿件等 POST 请求重复提交会重复执行，默认不重试，
+// 可以通过 Request.Retry 为确认可以重复提交的接口开启；视频分片上传有单独的重试，不受该策略影响
+type RetryPolicy struct {
+	// MaxRetries 最大重试次数
+	MaxRetries int
+
+	// Backoff 第一次重试前的等待时间，之后每次翻倍，默认500ms
+	Backoff time.Duration
+
+	// MaxBackoff 最长等待时间，默认10s
+	MaxBackoff time.Duration
+
+	// Codes 需要重试的业务码，默认为 CodeTooManyRequests
+	Codes []Code
+}
+
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.Backoff
+	for i := 0; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	return backoff
+}
+
+// retryable 判断是否需要重试
+func (p RetryPolicy) retryable(request *http.Request, resp *http.Response, err error) (bool, error) {
+	if err != nil {
+		return request.Context().Err() == nil, nil
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return true, nil
+	}
+
+	code, err := peekCode(resp)
+	if err != nil || code == nil {
+		return false, err
+	}
+	for _, each := range p.Codes {
+		if *code == each {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+type retryKey struct{}
+
+// withRetry 允许重试 ctx 中的非幂等请求
+func withRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+// shouldRetry 是否可以重试：视频分片上传不重试，POST 等非幂等请求需要通过 withRetry 开启，请求体需要可以重放
+func shouldRetry(request *http.Request) bool {
+	if Endpoint(request.URL) == "upos" {
+		return false
+	}
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		if retry, _ := request.Context().Value(retryKey{}).(bool); !retry {
+			return false
+		}
+	}
+
+	return request.Body == nil || request.Body == http.NoBody || request.GetBody != nil
+}
+
+// retryMiddleware 按策略重试，见 shouldRetry
+func (c *Client) retryMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			if !shouldRetry(request) {
+				return next(request)
+			}
+
+			for attempt := 0; ; attempt++ {
+				attemptRequest := request
+				if attempt > 0 && request.GetBody != nil {
+					body, err := request.GetBody()
+					if err != nil {
+						return nil, err
+					}
+					attemptRequest = request.Clone(request.Context())
+					attemptRequest.Body = body
+				}
+
+				resp, err := next(attemptRequest)
+				if attempt >= c.retryPolicy.MaxRetries {
+					return resp, err
+				}
+				retry, peekErr := c.retryPolicy.retryable(request, resp, err)
+				if peekErr != nil {
+					return nil, peekErr
+				}
+				if !retry {
+					return resp, err
+				}
+				if resp != nil {
+					_, _ = io.Copy(io.Discard, resp.Body)
+					_ = resp.Body.Close()
+				}
+
+				endpoint := Endpoint(request.URL)
+				c.metrics.IncRetry(endpoint)
+				c.log(request.Context(), SubsystemHTTP, LevelDebug, "retry http request",
+					KV(FieldEndpoint, endpoint), KV("attempt", attempt+1), KV(FieldError, err))
+
+				timer := time.NewTimer(c.retryPolicy.backoff(attempt))
+				select {
+				case <-request.Context().Done():
+					timer.Stop()
+					return nil, request.Context().Err()
+				case <-timer.C:
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package bilibili_go
+
+import (
+	"context"
+	"net/http"
+)
+
+// SpanAttribute span 属性
+type SpanAttribute struct {
+	Key   string
+	Value any
+}
+
+// Attr 创建 span 属性
+func Attr(key string, value any) SpanAttribute {
+	return SpanAttribute{Key: key, Value: value}
+}
+
+// Tracer 链路追踪接口，可以对接 OpenTelemetry 等追踪系统，参考 tracing/otel
+type Tracer interface {
+	// Start 以 ctx 中的 span 为父节点创建 span，返回携带新 span 的 ctx
+	Start(ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span)
+}
+
+// Span 一次操作
+type Span interface {
+	// SetAttributes 设置属性
+	SetAttributes(attrs ...SpanAttribute)
+
+	// End 结束 span，err 不为nil时标记为失败
+	End(err error)
+}
+
+// span 属性名
+const (
+	AttrEndpoint   = "bilibili.endpoint"
+	AttrCode       = "bilibili.code"
+	AttrMid        = "bilibili.mid"
+	AttrChunk      = "bilibili.upload.chunk"
+	AttrChunks     = "bilibili.upload.chunks"
+	AttrUploadID   = "bilibili.upload.id"
+	AttrFilename   = "bilibili.upload.filename"
+	AttrSize       = "bilibili.upload.size"
+	AttrAid        = "bilibili.aid"
+	AttrBvid       = "bilibili.bvid"
+	AttrVideos     = "bilibili.videos"
+	AttrRefresh    = "bilibili.refresh"
+	AttrHTTPMethod = "http.method"
+	AttrHTTPStatus = "http.status_code"
+)
+
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, _ string, _ ...SpanAttribute) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...SpanAttribute) {}
+
+func (nopSpan) End(error) {}
+
+type spanAttributesKey struct{}
+
+// withSpanAttributes 为 ctx 中后续发出的http请求的 span 附加属性，比如分片序号
+func withSpanAttributes(ctx context.Context, attrs ...SpanAttribute) context.Context {
+	parent, _ := ctx.Value(spanAttributesKey{}).([]SpanAttribute)
+
+	return context.WithValue(ctx, spanAttributesKey{}, append(append([]SpanAttribute(nil), parent...), attrs...))
+}
+
+// startSpan 创建 span 并附带当前用户 mid
+func (c *Client) startSpan(ctx context.Context, name string, attrs ...SpanAttribute) (context.Context, Span) {
+	return c.tracer.Start(ctx, name, append(attrs, Attr(AttrMid, c.getMid()))...)
+}
+
+// tracingMiddleware 为每个http请求创建子 span
+func (c *Client) tracingMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (resp *http.Response, err error) {
+			endpoint := Endpoint(request.URL)
+			parent, _ := request.Context().Value(spanAttributesKey{}).([]SpanAttribute)
+			attrs := append(append([]SpanAttribute(nil), parent...), Attr(AttrEndpoint, endpoint), Attr(AttrHTTPMethod, request.Method))
+
+			ctx, span := c.startSpan(request.Context(), "HTTP "+request.Method+" "+endpoint, attrs...)
+			defer func() { span.End(err) }()
+
+			resp, err = next(request.WithContext(ctx))
+			if err != nil {
+				return resp, err
+			}
+			span.SetAttributes(Attr(AttrHTTPStatus, resp.StatusCode))
+
+			code, err := peekCode(resp)
+			if err != nil {
+				return nil, err
+			}
+			if code != nil {
+				span.SetAttributes(Attr(AttrCode, int(*code)))
+			}
+
+			return resp, nil
+		}
+	}
+}
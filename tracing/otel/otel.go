@@ -0,0 +1,81 @@
+// Package otel 将 bilibili_go.Tracer 对接到 OpenTelemetry
+//
+//	client := bilibili_go.NewClient(
+//		bilibili_go.WithTracer(otel.NewTracer(nil)), // 使用全局的 TracerProvider
+//	)
+package otel
+
+import (
+	"context"
+	"fmt"
+
+	bilibili_go "github.com/kainhuck/bilibili-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName 创建 tracer 时使用的名称
+const InstrumentationName = "github.com/kainhuck/bilibili-go"
+
+var _ bilibili_go.Tracer = (*Tracer)(nil)
+
+// Tracer 实现 bilibili_go.Tracer
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer 使用 provider 创建 Tracer，provider 为nil时使用全局的 TracerProvider
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &Tracer{tracer: provider.Tracer(InstrumentationName)}
+}
+
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...bilibili_go.SpanAttribute) (context.Context, bilibili_go.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(convert(attrs)...))
+
+	return ctx, &Span{span: span}
+}
+
+// Span 实现 bilibili_go.Span
+type Span struct {
+	span trace.Span
+}
+
+func (s *Span) SetAttributes(attrs ...bilibili_go.SpanAttribute) {
+	s.span.SetAttributes(convert(attrs)...)
+}
+
+func (s *Span) End(err error) {
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+func convert(attrs []bilibili_go.SpanAttribute) []attribute.KeyValue {
+	result := make([]attribute.KeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		switch v := attr.Value.(type) {
+		case string:
+			result = append(result, attribute.String(attr.Key, v))
+		case bool:
+			result = append(result, attribute.Bool(attr.Key, v))
+		case int:
+			result = append(result, attribute.Int(attr.Key, v))
+		case int64:
+			result = append(result, attribute.Int64(attr.Key, v))
+		case float64:
+			result = append(result, attribute.Float64(attr.Key, v))
+		default:
+			result = append(result, attribute.String(attr.Key, fmt.Sprint(v)))
+		}
+	}
+
+	return result
+}
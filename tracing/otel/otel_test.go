@@ -0,0 +1,77 @@
+package otel
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	bilibili_go "github.com/kainhuck/bilibili-go"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type roundTripFunc func(request *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+func TestTracer(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	client := bilibili_go.NewClient(
+		bilibili_go.WithRefreshInterval(0),
+		bilibili_go.WithTracer(NewTracer(provider)),
+		bilibili_go.WithHttpClient(&http.Client{
+			Transport: roundTripFunc(func(request *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"code":0,"data":{"aid":1,"bvid":"BV1xx411c7mD"}}`)),
+					Request:    request,
+				}, nil
+			}),
+		}),
+	)
+
+	ctx, root := provider.Tracer("test").Start(context.Background(), "root")
+	if _, err := client.SubmitVideoContext(ctx, &bilibili_go.SubmitRequest{}); err != nil {
+		t.Fatalf("SubmitVideoContext() error = %v", err)
+	}
+	root.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("got %v spans, want 3", len(spans))
+	}
+
+	// span 按结束顺序导出
+	httpSpan, submitSpan, rootSpan := spans[0], spans[1], spans[2]
+	tests := []struct {
+		name   string
+		span   tracetest.SpanStub
+		parent tracetest.SpanStub
+		attr   attribute.KeyValue
+	}{
+		{"submit", submitSpan, rootSpan, attribute.String(bilibili_go.AttrBvid, "BV1xx411c7mD")},
+		{"http", httpSpan, submitSpan, attribute.Int(bilibili_go.AttrCode, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.span.Parent.SpanID() != tt.parent.SpanContext.SpanID() {
+				t.Errorf("span %v parent = %v, want %v", tt.span.Name, tt.span.Parent.SpanID(), tt.parent.SpanContext.SpanID())
+			}
+			found := false
+			for _, attr := range tt.span.Attributes {
+				found = found || attr == tt.attr
+			}
+			if !found {
+				t.Errorf("span %v attributes = %v, want %v", tt.span.Name, tt.span.Attributes, tt.attr)
+			}
+		})
+	}
+}
@@ -0,0 +1,378 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kainhuck/bilibili-go/internal/utils"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultUploadChunkSize 预上传接口没有返回 chunk_size 时的分片大小
+	defaultUploadChunkSize = 10 * utils.MB
+
+	// defaultUploadThreads 预上传接口没有返回 threads 时同时上传的分片数
+	defaultUploadThreads = 3
+)
+
+// UploadVideoReaderAt 从 io.ReaderAt 上传视频，比如 *os.File，size 为视频大小，不会将整个文件读入内存
+func (c *Client) UploadVideoReaderAt(ctx context.Context, filename string, reader io.ReaderAt, size int64, opts ...UploadOption) (*SubmitVideo, error) {
+	return c.uploadVideo(ctx, filename, io.NewSectionReader(reader, 0, size), size, applyUploadOptions(opts...))
+}
+
+// UploadVideoStream 从只能顺序读取的 reader 上传视频，比如 http 响应，size 为视频大小，未知时传-1。
+// 预上传以及每个分片请求都需要视频总大小，所以大小未知时需要先读完 reader：
+// 不超过一个分片的视频直接在内存中上传，否则写入临时文件（需要与视频大小相同的磁盘空间，可以通过 WithTempDir 指定目录）再上传
+func (c *Client) UploadVideoStream(ctx context.Context, filename string, reader io.Reader, size int64, opts ...UploadOption) (*SubmitVideo, error) {
+	opt := applyUploadOptions(opts...)
+	if size >= 0 {
+		return c.uploadVideo(ctx, filename, reader, size, opt)
+	}
+
+	limit := opt.ChunkSize
+	if limit <= 0 {
+		limit = defaultUploadChunkSize
+	}
+	head, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(head)) <= limit {
+		return c.uploadVideo(ctx, filename, bytes.NewReader(head), int64(len(head)), opt)
+	}
+
+	file, size, err := spoolTempFile(opt.TempDir, io.MultiReader(bytes.NewReader(head), reader))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	return c.uploadVideo(ctx, filename, io.NewSectionReader(file, 0, size), size, opt)
+}
+
+// spoolTempFile 将 reader 写入 dir 中的临时文件，返回文件和大小
+func spoolTempFile(dir string, reader io.Reader) (*os.File, int64, error) {
+	file, err := os.CreateTemp(dir, "bilibili-upload-*")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	size, err := io.Copy(file, reader)
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return nil, 0, err
+	}
+
+	return file, size, nil
+}
+
+// ResumeUpload 继续上传之前中断的视频，filename、size 以及内容需要与之前上传时一致，只会上传缺失的分片，
+// 需要通过 WithUploadSessionStore 设置会话存储。没有会话时返回 ErrUploadSessionNotFound，
+// 会话已被服务端废弃时返回 ErrUploadSessionExpired 并删除会话，此时需要重新上传。分片大小不能修改，WithChunkSize 会被忽略
+func (c *Client) ResumeUpload(ctx context.Context, filename string, reader io.ReaderAt, size int64, opts ...UploadOption) (video *SubmitVideo, err error) {
+	if c.uploadSessions == nil {
+		return nil, errors.New("upload session store not set")
+	}
+
+	fingerprint, err := contentFingerprint(reader, size)
+	if err != nil {
+		return nil, err
+	}
+	session, err := c.uploadSessions.LoadUploadSession(uploadSessionKey(filename, size, fingerprint))
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrUploadSessionNotFound
+	}
+	// 自定义的存储可能不按 key 区分会话，内容不一致时不能继续上传
+	if session.Filename != filename || session.Size != size || session.Fingerprint != fingerprint {
+		return nil, fmt.Errorf("%w: content changed", ErrUploadSessionNotFound)
+	}
+	opt := applyUploadOptions(opts...)
+	opt.apply(session)
+
+	start := time.Now()
+	ctx, span := c.startSpan(ctx, "ResumeUpload", Attr(AttrFilename, filename), Attr(AttrSize, size), Attr(AttrUploadID, session.UploadID))
+	ctx = withLogFields(withRequestID(ctx), KV("filename", filename), KV(FieldUploadID, session.UploadID), KV("line", session.Line))
+	defer func() {
+		c.metrics.ObserveUpload(size, time.Since(start), err)
+		span.End(err)
+	}()
+
+	c.log(ctx, SubsystemUpload, LevelInfo, "resume upload", KV("completed", len(session.Parts)), KV("parts", session.Chunks()))
+
+	return c.finishUpload(ctx, session, io.NewSectionReader(reader, 0, size), opt)
+}
+
+// uploadVideo 视频上传，从 reader 顺序读取 size 字节
+func (c *Client) uploadVideo(ctx context.Context, filename string, reader io.Reader, size int64, opt *uploadOptions) (video *SubmitVideo, err error) {
+	start := time.Now()
+	ctx, span := c.startSpan(ctx, "UploadVideo", Attr(AttrFilename, filename), Attr(AttrSize, size))
+	ctx = withLogFields(withRequestID(ctx), KV("filename", filename))
+	defer func() {
+		c.metrics.ObserveUpload(size, time.Since(start), err)
+		span.End(err)
+	}()
+
+	// 可以随机读取时计算内容指纹，用于继续上传时校验内容
+	var fingerprint string
+	if readerAt, ok := reader.(io.ReaderAt); ok {
+		if fingerprint, err = contentFingerprint(readerAt, size); err != nil {
+			return nil, err
+		}
+	}
+
+	// 依次尝试各条线路，还没有分片上传成功时才切换线路，需要 reader 可以 Seek
+	lines := c.uploadLines(ctx, opt)
+	for i, line := range lines {
+		var session *UploadSession
+		video, session, err = c.uploadVideoOnLine(ctx, span, filename, fingerprint, reader, size, opt, line)
+		if err == nil || i == len(lines)-1 || ctx.Err() != nil || (session != nil && len(session.Parts) > 0) {
+			return video, err
+		}
+
+		seeker, ok := reader.(io.Seeker)
+		if !ok {
+			return nil, err
+		}
+		if _, seekErr := seeker.Seek(0, io.SeekStart); seekErr != nil {
+			return nil, err
+		}
+		c.log(ctx, SubsystemUpload, LevelWarn, "upload line failed, try next line", KV("line", line.Name),
+			KV("next", lines[i+1].Name), KV(FieldError, err))
+	}
+
+	return nil, errors.New("no upload line")
+}
+
+// uploadVideoOnLine 使用指定线路上传视频，返回的会话用于判断是否已经有分片上传成功
+func (c *Client) uploadVideoOnLine(ctx context.Context, span Span, filename string, fingerprint string, reader io.Reader, size int64, opt *uploadOptions, line UploadLine) (*SubmitVideo, *UploadSession, error) {
+	ctx = withLogFields(ctx, KV("line", line.Name))
+
+	// 调接口上传
+	// 1. 预上传
+	preResp, err := c.preUpload(ctx, filename, size, line)
+	if err != nil {
+		return nil, nil, err
+	}
+	if preResp.OK != 1 {
+		return nil, nil, fmt.Errorf("[preUpload] upload failed code: %v", preResp.OK)
+	}
+
+	session := newUploadSession(filename, size, preResp)
+	session.Fingerprint = fingerprint
+	session.Line = line.Name
+	if opt.ChunkSize > 0 {
+		session.ChunkSize = opt.ChunkSize
+	}
+	opt.apply(session)
+
+	// 2. 获取 upload_id
+	uploadIDResp, err := c.getUploadID(ctx, session.URI, session.Auth, session.BizID, size, session.ChunkSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	if uploadIDResp.OK != 1 {
+		return nil, nil, fmt.Errorf("[getUploadID] upload failed code: %v", uploadIDResp.OK)
+	}
+	span.SetAttributes(Attr(AttrUploadID, uploadIDResp.UploadID))
+	ctx = withLogFields(ctx, KV(FieldUploadID, uploadIDResp.UploadID))
+
+	session.UploadID = uploadIDResp.UploadID
+	c.saveUploadSession(ctx, session)
+
+	video, err := c.finishUpload(ctx, session, reader, opt)
+
+	return video, session, err
+}
+
+// finishUpload 上传会话中缺失的分片并完成上传，会话过期时删除会话
+func (c *Client) finishUpload(ctx context.Context, session *UploadSession, reader io.Reader, opt *uploadOptions) (*SubmitVideo, error) {
+	// 3. 分片上传
+	progress := newUploadProgress(session, opt.Progress, opt.ProgressInterval)
+	err := c.uploadChunks(withBandwidthLimiter(ctx, opt.Bandwidth), session, reader, progress)
+	progress.close()
+	if errors.Is(err, ErrUploadSessionExpired) {
+		c.deleteUploadSession(ctx, session)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 视频上传完成
+	checkResp, err := c.uploadCheck(ctx, session.URI, session.Auth, session.Filename, session.UploadID, session.BizID, session.Parts)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkResp.OK != 1 {
+		return nil, fmt.Errorf("[uploadCheck] upload failed code: %v", checkResp.OK)
+	}
+	c.deleteUploadSession(ctx, session)
+
+	c.log(ctx, SubsystemUpload, LevelInfo, "video upload finished success", KV("cid", session.BizID))
+
+	return &SubmitVideo{
+		Filename: strings.Split(filepath.Base(session.UposURI), ".")[0],
+		Title:    strings.Split(session.Filename, ".")[0],
+		Desc:     "",
+		CID:      session.BizID,
+	}, nil
+}
+
+// saveUploadSession 保存会话，没有内容指纹的会话无法继续上传，不保存
+func (c *Client) saveUploadSession(ctx context.Context, session *UploadSession) {
+	if c.uploadSessions == nil || session.Fingerprint == "" {
+		return
+	}
+
+	session.saveMu.Lock()
+	defer session.saveMu.Unlock()
+	if err := c.uploadSessions.SaveUploadSession(session.Key(), session); err != nil {
+		c.log(ctx, SubsystemUpload, LevelWarn, "SaveUploadSession failed", KV(FieldError, err))
+	}
+}
+
+func (c *Client) deleteUploadSession(ctx context.Context, session *UploadSession) {
+	if c.uploadSessions == nil || session.Fingerprint == "" {
+		return
+	}
+	if err := c.uploadSessions.DeleteUploadSession(session.Key()); err != nil {
+		c.log(ctx, SubsystemUpload, LevelWarn, "DeleteUploadSession failed", KV(FieldError, err))
+	}
+}
+
+// uploadChunks 顺序读取会话中缺失的分片并发上传，最多同时持有 Threads 个分片的缓冲区，
+// 任意分片失败并且重试后仍然失败时停止读取并取消其他分片
+func (c *Client) uploadChunks(ctx context.Context, session *UploadSession, reader io.Reader, progress *uploadProgress) error {
+	size, chunkSize, chunks, concurrency := session.Size, session.ChunkSize, session.Chunks(), session.Threads
+	c.log(ctx, SubsystemUpload, LevelInfo, "start upload file", KV("parts", chunks), KV("size", size),
+		KV("chunk_size", chunkSize), KV("threads", concurrency))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	// 缓冲区在第一次使用时分配，小文件不会分配完整的分片大小
+	buffers := make(chan []byte, concurrency)
+	for i := 0; i < concurrency; i++ {
+		buffers <- nil
+	}
+
+	for number := 1; number <= chunks; number++ {
+		offset, partSize := int64(number-1)*chunkSize, session.partSize(number)
+
+		// 跳过已经上传的分片
+		if session.completed(number) {
+			if err := skip(reader, partSize); err != nil {
+				fail(fmt.Errorf("skip part %d: %w", number, err))
+				break
+			}
+			continue
+		}
+
+		var buf []byte
+		select {
+		case buf = <-buffers:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		if int64(cap(buf)) < partSize {
+			buf = make([]byte, partSize)
+		}
+		part := buf[:partSize]
+		if _, err := io.ReadFull(reader, part); err != nil {
+			fail(fmt.Errorf("read part %d: %w", number, err))
+			break
+		}
+
+		wg.Add(1)
+		go func(part []byte, number int, offset int64) {
+			defer wg.Done()
+			defer func() { buffers <- part }()
+
+			start := time.Now()
+			etag, err := c.uploadPart(withChunkProgress(ctx, progress.chunk(number)), session, number, part, offset)
+			if err != nil {
+				fail(fmt.Errorf("upload part %d: %w", number, err))
+				return
+			}
+			session.complete(number, etag)
+			progress.completeChunk(number, int64(len(part)))
+			c.saveUploadSession(ctx, session)
+			c.log(ctx, SubsystemUpload, LevelInfo, "part finished", KV("part", number), KV("duration", time.Since(start)))
+		}(part, number, offset)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	return ctx.Err()
+}
+
+// uploadPart 上传一个分片，失败后按会话的 ChunkRetry 和 ChunkRetryDelay 重试，每次请求的超时时间为 ChunkTimeout
+func (c *Client) uploadPart(ctx context.Context, session *UploadSession, number int, part []byte, offset int64) (string, error) {
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if session.ChunkTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, session.ChunkTimeout)
+		}
+
+		start := time.Now()
+		etag, err := c.uploadFileClip(attemptCtx, session.URI, session.Auth, session.UploadID, number, session.Chunks(), len(part), offset, offset+int64(len(part)), session.Size, part)
+		cancel()
+		c.metrics.ObserveUploadChunk(len(part), time.Since(start), err)
+
+		if err == nil || attempt >= session.ChunkRetry || ctx.Err() != nil || errors.Is(err, ErrUploadSessionExpired) {
+			return etag, err
+		}
+
+		c.metrics.IncRetry("upos")
+		c.log(ctx, SubsystemUpload, LevelWarn, "retry part", KV("part", number), KV("attempt", attempt+1), KV(FieldError, err))
+
+		select {
+		case <-time.After(session.ChunkRetryDelay):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// skip 跳过 reader 中的 n 个字节
+func skip(reader io.Reader, n int64) error {
+	if seeker, ok := reader.(io.Seeker); ok {
+		_, err := seeker.Seek(n, io.SeekCurrent)
+		return err
+	}
+	_, err := io.CopyN(io.Discard, reader, n)
+
+	return err
+}
@@ -0,0 +1,184 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kainhuck/bilibili-go/internal/net"
+	"github.com/kainhuck/bilibili-go/internal/utils"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// lineProbeSize 测速时上传的字节数
+	lineProbeSize = 1 * utils.MB
+
+	// lineProbeTimeout 单条线路测速的超时时间
+	lineProbeTimeout = 10 * time.Second
+)
+
+// UploadLine 视频上传线路，对应预上传接口的 upcdn 和 zone 参数
+type UploadLine struct {
+	Name  string
+	Upcdn string
+	Zone  string
+}
+
+// Host 线路的上传域名
+func (l UploadLine) Host() string {
+	return "upos-" + l.Zone + "-upcdn" + l.Upcdn + ".bilivideo.com"
+}
+
+func (l UploadLine) String() string {
+	return l.Name
+}
+
+var (
+	LineBda2  = UploadLine{Name: "bda2", Upcdn: "bda2", Zone: "cs"}
+	LineWs    = UploadLine{Name: "ws", Upcdn: "ws", Zone: "cs"}
+	LineQn    = UploadLine{Name: "qn", Upcdn: "qn", Zone: "cs"}
+	LineBldsa = UploadLine{Name: "bldsa", Upcdn: "bldsa", Zone: "cs"}
+	LineTxa   = UploadLine{Name: "txa", Upcdn: "txa", Zone: "cs"}
+	LineAlia  = UploadLine{Name: "alia", Upcdn: "alia", Zone: "cs"}
+
+	// UploadLines 所有已知的上传线路，txa 和 alia 适合海外
+	UploadLines = []UploadLine{LineBda2, LineWs, LineQn, LineBldsa, LineTxa, LineAlia}
+
+	// DefaultUploadLines 默认的上传线路，按顺序尝试
+	DefaultUploadLines = []UploadLine{LineBldsa, LineBda2, LineWs, LineQn}
+)
+
+// UploadLineByName 按名称查找线路
+func UploadLineByName(name string) (UploadLine, error) {
+	for _, line := range UploadLines {
+		if line.Name == name {
+			return line, nil
+		}
+	}
+
+	return UploadLine{}, fmt.Errorf("unknown upload line %q", name)
+}
+
+// LineProbe 线路测速结果
+type LineProbe struct {
+	Line UploadLine
+
+	// Latency 请求延迟
+	Latency time.Duration
+
+	// Throughput 上传速度，字节/秒
+	Throughput float64
+
+	Err error
+}
+
+// ProbeUploadLines 测试线路的延迟和上传速度，lines 为空时测试所有已知线路，
+// 延迟并发测试，上传速度依次测试避免互相抢占带宽，返回结果按上传速度从快到慢排序，失败的线路排在最后
+func (c *Client) ProbeUploadLines(ctx context.Context, lines ...UploadLine) []LineProbe {
+	if len(lines) == 0 {
+		lines = UploadLines
+	}
+
+	results := make([]LineProbe, len(lines))
+	var wg sync.WaitGroup
+	for i, line := range lines {
+		wg.Add(1)
+		go func(i int, line UploadLine) {
+			defer wg.Done()
+			results[i] = c.probeLineLatency(ctx, line)
+		}(i, line)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if results[i].Err == nil {
+			c.probeLineThroughput(ctx, &results[i])
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if (results[i].Err == nil) != (results[j].Err == nil) {
+			return results[i].Err == nil
+		}
+		return results[i].Throughput > results[j].Throughput
+	})
+
+	return results
+}
+
+// probeLineLatency 请求线路的 /OK 测试延迟
+func (c *Client) probeLineLatency(ctx context.Context, line UploadLine) LineProbe {
+	ctx, cancel := context.WithTimeout(ctx, lineProbeTimeout)
+	defer cancel()
+
+	result := LineProbe{Line: line}
+	start := time.Now()
+	if result.Err = probeRequest(c.getHttpClient(false).SetContext(ctx).Get("https://" + line.Host() + "/OK")); result.Err != nil {
+		return result
+	}
+	result.Latency = time.Since(start)
+
+	return result
+}
+
+// probeLineThroughput 向线路的 /OK 上传 lineProbeSize 字节测试速度
+func (c *Client) probeLineThroughput(ctx context.Context, result *LineProbe) {
+	ctx, cancel := context.WithTimeout(ctx, lineProbeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	uri := "https://" + result.Line.Host() + "/OK"
+	if result.Err = probeRequest(c.getHttpClient(false).SetContext(ctx).Post(uri).SendBody(bytes.NewReader(make([]byte, lineProbeSize)))); result.Err != nil {
+		return
+	}
+	result.Throughput = float64(lineProbeSize) / time.Since(start).Seconds()
+
+	c.log(ctx, SubsystemUpload, LevelDebug, "probe upload line", KV("line", result.Line.Name),
+		KV("latency", result.Latency), KV("throughput", int64(result.Throughput)))
+}
+
+func probeRequest(client *net.HttpClient) error {
+	resp, body, err := client.End()
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, body)
+	}
+
+	return nil
+}
+
+// uploadLines 本次上传依次尝试的线路，开启测速时按测速结果排序并去掉不可用的线路
+func (c *Client) uploadLines(ctx context.Context, opt *uploadOptions) []UploadLine {
+	lines, probe := c.defaultUploadLines, c.probeUploadLines
+	if len(opt.Lines) > 0 {
+		lines = opt.Lines
+	}
+	if len(lines) == 0 {
+		lines = DefaultUploadLines
+	}
+	if opt.LineProbe != nil {
+		probe = *opt.LineProbe
+	}
+	if !probe {
+		return lines
+	}
+
+	var probed []UploadLine
+	for _, result := range c.ProbeUploadLines(ctx, lines...) {
+		if result.Err != nil {
+			c.log(ctx, SubsystemUpload, LevelWarn, "upload line unavailable", KV("line", result.Line.Name), KV(FieldError, result.Err))
+			continue
+		}
+		probed = append(probed, result.Line)
+	}
+	if len(probed) == 0 {
+		return lines
+	}
+
+	return probed
+}
@@ -0,0 +1,136 @@
+package bilibili_go
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lineServer 模拟上传线路，broken 中的线路预上传和测速都会失败，slow 中的线路测速较慢，
+// maxProbing 记录同时进行的上传测速数量
+type lineServer struct {
+	*uploadServer
+	mu         sync.Mutex
+	broken     map[string]bool
+	slow       map[string]bool
+	upcdns     []string
+	uploaded   string
+	probing    int
+	maxProbing int
+}
+
+func (s *lineServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.Header.Get("X-Original-Host")
+	line := strings.TrimSuffix(strings.TrimPrefix(host, "upos-cs-upcdn"), ".bilivideo.com")
+
+	switch r.URL.Path {
+	case "/preupload":
+		upcdn := r.URL.Query().Get("upcdn")
+		s.mu.Lock()
+		s.upcdns = append(s.upcdns, upcdn)
+		s.mu.Unlock()
+		if s.broken[upcdn] {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		s.mu.Lock()
+		s.uploaded = upcdn
+		s.mu.Unlock()
+	case "/OK":
+		if s.broken[line] {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.Method == http.MethodPost {
+			s.mu.Lock()
+			s.probing++
+			if s.probing > s.maxProbing {
+				s.maxProbing = s.probing
+			}
+			s.mu.Unlock()
+			defer func() {
+				s.mu.Lock()
+				s.probing--
+				s.mu.Unlock()
+			}()
+		}
+		_, _ = io.Copy(io.Discard, r.Body)
+		if s.slow[line] && r.Method == http.MethodPost {
+			time.Sleep(100 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte("OK"))
+		return
+	}
+
+	s.uploadServer.ServeHTTP(w, r)
+}
+
+func newLineServer() *lineServer {
+	return &lineServer{uploadServer: newUploadServer(), broken: make(map[string]bool), slow: make(map[string]bool)}
+}
+
+func TestUploadLineFallback(t *testing.T) {
+	content := testVideo(testChunkSize + 1)
+
+	server := newLineServer()
+	server.broken["bldsa"] = true
+	client := newTestClient(t, server)
+	if _, err := client.UploadVideo("test.mp4", content); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(server.upcdns, []string{"bldsa", "bda2"}) {
+		t.Errorf("tried lines %v, want [bldsa bda2]", server.upcdns)
+	}
+
+	// 指定线路
+	server = newLineServer()
+	client = newTestClient(t, server, WithDefaultUploadLines(LineQn))
+	if _, err := client.UploadVideoContext(context.Background(), "test.mp4", content, WithUploadLine(LineTxa)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(server.upcdns, []string{"txa"}) {
+		t.Errorf("tried lines %v, want [txa]", server.upcdns)
+	}
+
+	// 所有线路都失败
+	server = newLineServer()
+	server.broken["ws"], server.broken["qn"] = true, true
+	client = newTestClient(t, server)
+	if _, err := client.UploadVideoContext(context.Background(), "test.mp4", content, WithUploadLine(LineWs, LineQn)); err == nil {
+		t.Error("upload should fail when all lines are broken")
+	}
+}
+
+func TestProbeUploadLines(t *testing.T) {
+	server := newLineServer()
+	server.broken["ws"] = true
+	server.slow["qn"] = true
+	client := newTestClient(t, server)
+
+	results := client.ProbeUploadLines(context.Background(), LineWs, LineQn, LineAlia)
+	var got []string
+	for _, result := range results {
+		got = append(got, result.Line.Name)
+	}
+	if !reflect.DeepEqual(got, []string{"alia", "qn", "ws"}) || results[2].Err == nil || results[0].Throughput <= 0 || results[0].Latency <= 0 {
+		t.Errorf("ProbeUploadLines() = %+v", results)
+	}
+	if server.maxProbing != 1 {
+		t.Errorf("%d throughput probes ran concurrently, want 1", server.maxProbing)
+	}
+
+	// 测速后使用最快的线路
+	_, err := client.UploadVideoContext(context.Background(), "test.mp4", testVideo(testChunkSize),
+		WithUploadLine(LineWs, LineQn, LineAlia), WithLineProbe(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.uploaded != "alia" || server.upcdns[0] != "alia" {
+		t.Errorf("uploaded with %q, tried %v", server.uploaded, server.upcdns)
+	}
+}
@@ -0,0 +1,198 @@
+package bilibili_go
+
+import (
+	"time"
+)
+
+// UploadOption 视频上传选项，未设置的选项使用预上传接口返回的值
+type UploadOption interface {
+	applyUpload(*uploadOptions)
+}
+
+type uploadOptions struct {
+	// ChunkSize 分片大小，只对新的上传生效，继续上传时使用会话中的分片大小
+	ChunkSize int64
+
+	// Threads 同时上传的分片数
+	Threads int
+
+	// ChunkRetry 分片失败后的重试次数，小于0表示未设置
+	ChunkRetry int
+
+	// ChunkRetryDelay 分片重试间隔，小于0表示未设置
+	ChunkRetryDelay time.Duration
+
+	// ChunkTimeout 单个分片请求的超时时间，小于0表示未设置
+	ChunkTimeout time.Duration
+
+	// Progress 进度回调
+	Progress func(UploadProgress)
+
+	// ProgressInterval 进度回调间隔
+	ProgressInterval time.Duration
+
+	// Bandwidth 本次上传的带宽限制
+	Bandwidth *BandwidthLimiter
+
+	// Lines 依次尝试的上传线路，为空则使用 Client 的默认线路
+	Lines []UploadLine
+
+	// LineProbe 是否在上传前测速，为nil则使用 Client 的设置
+	LineProbe *bool
+
+	// TempDir 大小未知的视频写入临时文件的目录，为空则使用 os.TempDir()
+	TempDir string
+}
+
+func applyUploadOptions(opts ...UploadOption) *uploadOptions {
+	opt := &uploadOptions{
+		ChunkRetry:      -1,
+		ChunkRetryDelay: -1,
+		ChunkTimeout:    -1,
+	}
+	for _, o := range opts {
+		o.applyUpload(opt)
+	}
+
+	return opt
+}
+
+// apply 用选项覆盖会话中的上传参数
+func (o *uploadOptions) apply(session *UploadSession) {
+	if o.Threads > 0 {
+		session.Threads = o.Threads
+	}
+	if o.ChunkRetry >= 0 {
+		session.ChunkRetry = o.ChunkRetry
+	}
+	if o.ChunkRetryDelay >= 0 {
+		session.ChunkRetryDelay = o.ChunkRetryDelay
+	}
+	if o.ChunkTimeout >= 0 {
+		session.ChunkTimeout = o.ChunkTimeout
+	}
+}
+
+type chunkSize int64
+
+func (c chunkSize) applyUpload(opt *uploadOptions) {
+	opt.ChunkSize = int64(c)
+}
+
+// WithChunkSize 设置分片大小，覆盖预上传接口返回的 chunk_size
+func WithChunkSize(size int64) UploadOption {
+	return chunkSize(size)
+}
+
+type uploadThreads int
+
+func (u uploadThreads) applyUpload(opt *uploadOptions) {
+	opt.Threads = int(u)
+}
+
+// WithUploadThreads 设置同时上传的分片数，覆盖预上传接口返回的 threads，内存占用约为分片大小乘以 threads
+func WithUploadThreads(threads int) UploadOption {
+	return uploadThreads(threads)
+}
+
+type chunkRetry struct {
+	retry int
+	delay time.Duration
+}
+
+func (c chunkRetry) applyUpload(opt *uploadOptions) {
+	opt.ChunkRetry = c.retry
+	opt.ChunkRetryDelay = c.delay
+}
+
+// WithChunkRetry 设置分片失败后的重试次数和间隔，覆盖预上传接口返回的 chunk_retry 和 chunk_retry_delay
+func WithChunkRetry(retry int, delay time.Duration) UploadOption {
+	return chunkRetry{retry: retry, delay: delay}
+}
+
+type chunkTimeout time.Duration
+
+func (c chunkTimeout) applyUpload(opt *uploadOptions) {
+	opt.ChunkTimeout = time.Duration(c)
+}
+
+// WithChunkTimeout 设置单个分片请求的超时时间，覆盖预上传接口返回的 timeout，为0则不超时
+func WithChunkTimeout(timeout time.Duration) UploadOption {
+	return chunkTimeout(timeout)
+}
+
+type progress func(UploadProgress)
+
+func (p progress) applyUpload(opt *uploadOptions) {
+	opt.Progress = p
+}
+
+// WithProgress 设置上传进度回调，每隔 WithProgressInterval（默认500毫秒）以及每个分片完成时调用，回调不会并发执行
+func WithProgress(f func(UploadProgress)) UploadOption {
+	return progress(f)
+}
+
+type progressInterval time.Duration
+
+func (p progressInterval) applyUpload(opt *uploadOptions) {
+	opt.ProgressInterval = time.Duration(p)
+}
+
+// WithProgressInterval 设置进度回调的间隔
+func WithProgressInterval(interval time.Duration) UploadOption {
+	return progressInterval(interval)
+}
+
+type bandwidthLimit struct {
+	limiter *BandwidthLimiter
+}
+
+func (b bandwidthLimit) applyUpload(opt *uploadOptions) {
+	opt.Bandwidth = b.limiter
+}
+
+// WithBandwidthLimiter 限制本次上传所有分片的总带宽，上传过程中可以通过 BandwidthLimiter.SetLimit 修改，
+// 同时受 WithUploadBandwidth 设置的 Client 总带宽限制
+func WithBandwidthLimiter(limiter *BandwidthLimiter) UploadOption {
+	return bandwidthLimit{limiter: limiter}
+}
+
+// WithBandwidthLimit 限制本次上传所有分片的总带宽，字节/秒，需要在上传过程中修改时使用 WithBandwidthLimiter
+func WithBandwidthLimit(bytesPerSecond int64) UploadOption {
+	return bandwidthLimit{limiter: NewBandwidthLimiter(bytesPerSecond)}
+}
+
+type uploadLines []UploadLine
+
+func (u uploadLines) applyUpload(opt *uploadOptions) {
+	opt.Lines = u
+}
+
+// WithUploadLine 指定本次上传的线路，按顺序尝试，还没有分片上传成功时失败会切换到下一条线路，
+// 只对新的上传生效，继续上传时使用会话中的线路
+func WithUploadLine(lines ...UploadLine) UploadOption {
+	return uploadLines(lines)
+}
+
+type lineProbe bool
+
+func (l lineProbe) applyUpload(opt *uploadOptions) {
+	probe := bool(l)
+	opt.LineProbe = &probe
+}
+
+// WithLineProbe 上传前是否对候选线路测速，开启后按上传速度排序并跳过不可用的线路
+func WithLineProbe(probe bool) UploadOption {
+	return lineProbe(probe)
+}
+
+type tempDir string
+
+func (t tempDir) applyUpload(opt *uploadOptions) {
+	opt.TempDir = string(t)
+}
+
+// WithTempDir 设置 UploadVideoStream 上传大小未知的视频时临时文件的目录，需要有与视频大小相同的可用空间
+func WithTempDir(dir string) UploadOption {
+	return tempDir(dir)
+}
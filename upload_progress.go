@@ -0,0 +1,262 @@
+package bilibili_go
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultProgressInterval 默认的进度回调间隔
+	defaultProgressInterval = 500 * time.Millisecond
+
+	// progressSpeedWindow 计算瞬时速度的时间窗口
+	progressSpeedWindow = 3 * time.Second
+)
+
+// UploadProgress 视频上传进度
+type UploadProgress struct {
+	Filename string
+	UploadID string
+
+	// TotalBytes 视频大小
+	TotalBytes int64
+
+	// SentBytes 已发送的字节数，包括正在上传的分片中已发送的部分，分片重试时会减去该分片已发送的字节
+	SentBytes int64
+
+	Chunks          int
+	CompletedChunks int
+
+	// Chunk 刚刚完成的分片序号，定时回调时为0
+	Chunk int
+
+	// Speed 最近3秒的速度，字节/秒，持续为0说明上传已经停滞
+	Speed float64
+
+	// AverageSpeed 本次上传的平均速度，字节/秒，继续上传时不包括之前已完成的分片
+	AverageSpeed float64
+
+	// Elapsed 本次上传已用时间
+	Elapsed time.Duration
+
+	// ETA 按 Speed 估算的剩余时间，Speed 为0时为-1
+	ETA time.Duration
+}
+
+type progressSample struct {
+	at   time.Time
+	sent int64
+}
+
+// uploadProgress 统计上传进度，定时以及每个分片完成时回调
+type uploadProgress struct {
+	mu              sync.Mutex
+	callbackMu      sync.Mutex // 保证回调不会并发执行
+	callback        func(UploadProgress)
+	filename        string
+	uploadID        string
+	total           int64
+	chunks          int
+	completedChunks int
+	completedBytes  int64
+	initialBytes    int64
+	inflight        map[int]int64 // 正在上传的分片已发送的字节数
+	start           time.Time
+	samples         []progressSample
+	stop            chan struct{}
+	done            chan struct{}
+}
+
+// newUploadProgress 创建进度统计并开始定时回调，callback 为nil时返回nil
+func newUploadProgress(session *UploadSession, callback func(UploadProgress), interval time.Duration) *uploadProgress {
+	if callback == nil {
+		return nil
+	}
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+
+	p := &uploadProgress{
+		callback: callback,
+		filename: session.Filename,
+		uploadID: session.UploadID,
+		total:    session.Size,
+		chunks:   session.Chunks(),
+		inflight: make(map[int]int64),
+		start:    time.Now(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, part := range session.Parts {
+		p.completedChunks++
+		p.completedBytes += session.partSize(part.PartNumber)
+	}
+	p.initialBytes = p.completedBytes
+	p.samples = []progressSample{{at: p.start, sent: p.completedBytes}}
+
+	go p.run(interval)
+
+	return p
+}
+
+func (p *uploadProgress) run(interval time.Duration) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.report(0)
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// close 停止定时回调并回调最终进度
+func (p *uploadProgress) close() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+	p.report(0)
+}
+
+// chunk 返回分片的进度，用于统计请求体已发送的字节
+func (p *uploadProgress) chunk(number int) *chunkProgress {
+	if p == nil {
+		return nil
+	}
+
+	return &chunkProgress{progress: p, number: number}
+}
+
+// completeChunk 分片上传完成
+func (p *uploadProgress) completeChunk(number int, size int64) {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	delete(p.inflight, number)
+	p.completedChunks++
+	p.completedBytes += size
+	p.mu.Unlock()
+
+	p.report(number)
+}
+
+func (p *uploadProgress) report(chunk int) {
+	now := time.Now()
+
+	p.mu.Lock()
+	sent := p.completedBytes
+	for _, n := range p.inflight {
+		sent += n
+	}
+
+	p.samples = append(p.samples, progressSample{at: now, sent: sent})
+	for len(p.samples) > 2 && now.Sub(p.samples[1].at) >= progressSpeedWindow {
+		p.samples = p.samples[1:]
+	}
+	oldest := p.samples[0]
+
+	progress := UploadProgress{
+		Filename:        p.filename,
+		UploadID:        p.uploadID,
+		TotalBytes:      p.total,
+		SentBytes:       sent,
+		Chunks:          p.chunks,
+		CompletedChunks: p.completedChunks,
+		Chunk:           chunk,
+		Elapsed:         now.Sub(p.start),
+		ETA:             -1,
+	}
+	p.mu.Unlock()
+
+	if d := now.Sub(oldest.at).Seconds(); d > 0 {
+		progress.Speed = float64(sent-oldest.sent) / d
+	}
+	if d := progress.Elapsed.Seconds(); d > 0 {
+		progress.AverageSpeed = float64(sent-p.initialBytes) / d
+	}
+	if progress.Speed > 0 {
+		progress.ETA = time.Duration(float64(progress.TotalBytes-sent) / progress.Speed * float64(time.Second))
+	} else if sent >= progress.TotalBytes {
+		progress.ETA = 0
+	}
+
+	p.callbackMu.Lock()
+	defer p.callbackMu.Unlock()
+	p.callback(progress)
+}
+
+// chunkProgress 单个分片的进度，通过 ctx 传给 uploadProgressMiddleware
+type chunkProgress struct {
+	progress *uploadProgress
+	number   int
+}
+
+// reset 分片开始发送或者重试
+func (c *chunkProgress) reset() {
+	c.progress.mu.Lock()
+	defer c.progress.mu.Unlock()
+
+	c.progress.inflight[c.number] = 0
+}
+
+func (c *chunkProgress) add(n int) {
+	c.progress.mu.Lock()
+	defer c.progress.mu.Unlock()
+
+	c.progress.inflight[c.number] += int64(n)
+}
+
+type chunkProgressKey struct{}
+
+func withChunkProgress(ctx context.Context, chunk *chunkProgress) context.Context {
+	if chunk == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, chunkProgressKey{}, chunk)
+}
+
+// progressReader 统计已读取（即已发送）的字节
+type progressReader struct {
+	io.ReadCloser
+	chunk *chunkProgress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.chunk.add(n)
+	}
+
+	return n, err
+}
+
+// uploadProgressMiddleware 统计分片请求体的发送进度，位于重试中间件内层，每次重试都会重新统计
+func (c *Client) uploadProgressMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			chunk, ok := request.Context().Value(chunkProgressKey{}).(*chunkProgress)
+			if !ok || request.Body == nil || request.Body == http.NoBody {
+				return next(request)
+			}
+
+			chunk.reset()
+			counted := request.Clone(request.Context())
+			counted.Body = &progressReader{ReadCloser: request.Body, chunk: chunk}
+
+			return next(counted)
+		}
+	}
+}
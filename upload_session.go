@@ -0,0 +1,234 @@
+package bilibili_go
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUploadSessionNotFound 没有可以继续的上传会话
+	ErrUploadSessionNotFound = errors.New("upload session not found")
+
+	// ErrUploadSessionExpired 上传会话已被服务端废弃，需要重新上传
+	ErrUploadSessionExpired = errors.New("upload session expired")
+)
+
+// fingerprintSize 计算内容指纹时读取的开头和结尾的字节数
+const fingerprintSize = 64 * 1024
+
+// UploadPart 已上传的分片
+type UploadPart struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"eTag"`
+}
+
+// UploadSession 分片上传会话，每个分片上传成功后都会保存到 UploadSessionStore，进程重启后可以通过 ResumeUpload 继续上传，
+// 只能顺序读取的视频无法在继续上传时校验内容，不会保存会话
+type UploadSession struct {
+	Filename    string       `json:"filename"`
+	Size        int64        `json:"size"`
+	Fingerprint string       `json:"fingerprint"` // 视频开头和结尾内容的指纹，见 contentFingerprint
+	Line        string       `json:"line"`        // 上传线路名称
+	URI         string       `json:"uri"`         // 分片上传地址
+	UposURI     string       `json:"upos_uri"`
+	Auth        string       `json:"auth"`
+	BizID       int          `json:"biz_id"`
+	UploadID    string       `json:"upload_id"`
+	ChunkSize   int64        `json:"chunk_size"`
+	Parts       []UploadPart `json:"parts"` // 已完成的分片，按分片序号排序
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+
+	// 以下为预上传接口返回的上传参数，可以通过 UploadOption 覆盖
+	Threads         int           `json:"threads"`
+	ChunkRetry      int           `json:"chunk_retry"`
+	ChunkRetryDelay time.Duration `json:"chunk_retry_delay"`
+	ChunkTimeout    time.Duration `json:"chunk_timeout"`
+
+	mu     sync.Mutex
+	saveMu sync.Mutex // 保证按顺序保存，避免旧的快照覆盖新的
+}
+
+// newUploadSession 根据预上传接口的响应创建会话，chunk_size 和 threads 为0时使用默认值
+func newUploadSession(filename string, size int64, preResp *PreUploadResponse) *UploadSession {
+	session := &UploadSession{
+		Filename:        filename,
+		Size:            size,
+		URI:             preResp.Uri(),
+		UposURI:         preResp.UposURI,
+		Auth:            preResp.Auth,
+		BizID:           preResp.BizID,
+		ChunkSize:       int64(preResp.ChunkSize),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+		Threads:         preResp.Threads,
+		ChunkRetry:      preResp.ChunkRetry,
+		ChunkRetryDelay: time.Duration(preResp.ChunkRetryDelay) * time.Second,
+		ChunkTimeout:    time.Duration(preResp.Timeout) * time.Second,
+	}
+	if session.ChunkSize <= 0 {
+		session.ChunkSize = defaultUploadChunkSize
+	}
+	if session.Threads <= 0 {
+		session.Threads = defaultUploadThreads
+	}
+
+	return session
+}
+
+// Key 会话在 UploadSessionStore 中的key
+func (s *UploadSession) Key() string {
+	return uploadSessionKey(s.Filename, s.Size, s.Fingerprint)
+}
+
+// Chunks 分片数量
+func (s *UploadSession) Chunks() int {
+	return int((s.Size + s.ChunkSize - 1) / s.ChunkSize)
+}
+
+// partSize 分片的大小，最后一个分片可能小于 ChunkSize
+func (s *UploadSession) partSize(number int) int64 {
+	offset := int64(number-1) * s.ChunkSize
+	if s.Size-offset < s.ChunkSize {
+		return s.Size - offset
+	}
+
+	return s.ChunkSize
+}
+
+// completed 分片是否已经上传
+func (s *UploadSession) completed(number int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := sort.Search(len(s.Parts), func(i int) bool { return s.Parts[i].PartNumber >= number })
+
+	return i < len(s.Parts) && s.Parts[i].PartNumber == number
+}
+
+// complete 记录上传成功的分片
+func (s *UploadSession) complete(number int, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := sort.Search(len(s.Parts), func(i int) bool { return s.Parts[i].PartNumber >= number })
+	if i < len(s.Parts) && s.Parts[i].PartNumber == number {
+		s.Parts[i].ETag = etag
+	} else {
+		s.Parts = append(s.Parts, UploadPart{})
+		copy(s.Parts[i+1:], s.Parts[i:])
+		s.Parts[i] = UploadPart{PartNumber: number, ETag: etag}
+	}
+	s.UpdatedAt = time.Now()
+}
+
+func (s *UploadSession) MarshalJSON() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type session UploadSession
+
+	return json.Marshal((*session)(s))
+}
+
+// uploadSessionKey 文件名、大小以及内容指纹都相同的视频使用同一个会话
+func uploadSessionKey(filename string, size int64, fingerprint string) string {
+	hash := sha1.Sum([]byte(filename + ":" + strconv.FormatInt(size, 10) + ":" + fingerprint))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// contentFingerprint 视频开头和结尾各 fingerprintSize 字节的 sha1，用于区分文件名和大小相同但内容不同的视频，
+// 不读取整个文件，大文件也可以很快算出
+func contentFingerprint(reader io.ReaderAt, size int64) (string, error) {
+	hash := sha1.New()
+	n := int64(fingerprintSize)
+	if size < n {
+		n = size
+	}
+	for _, offset := range []int64{0, size - n} {
+		if _, err := io.Copy(hash, io.NewSectionReader(reader, offset, n)); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// UploadSessionStore 上传会话存储
+type UploadSessionStore interface {
+	// LoadUploadSession 加载会话，不存在时返回 nil
+	LoadUploadSession(key string) (*UploadSession, error)
+
+	// SaveUploadSession 保存会话，每个分片上传成功后都会调用
+	SaveUploadSession(key string, session *UploadSession) error
+
+	// DeleteUploadSession 上传完成或会话过期后删除
+	DeleteUploadSession(key string) error
+}
+
+type fileUploadSessionStore struct {
+	dir string
+}
+
+func (f fileUploadSessionStore) path(key string) string {
+	return filepath.Join(f.dir, key+".json")
+}
+
+func (f fileUploadSessionStore) LoadUploadSession(key string) (*UploadSession, error) {
+	bts, err := os.ReadFile(f.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session UploadSession
+	if err = json.Unmarshal(bts, &session); err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (f fileUploadSessionStore) SaveUploadSession(key string, session *UploadSession) error {
+	bts, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(f.dir, 0755); err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免进程在写入时退出导致会话损坏
+	tmp := f.path(key) + ".tmp"
+	if err = os.WriteFile(tmp, bts, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, f.path(key))
+}
+
+func (f fileUploadSessionStore) DeleteUploadSession(key string) error {
+	err := os.Remove(f.path(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// NewFileUploadSessionStore 将上传会话以json文件保存在 dir 目录下
+func NewFileUploadSessionStore(dir string) UploadSessionStore {
+	return &fileUploadSessionStore{dir: dir}
+}
@@ -0,0 +1,413 @@
+package bilibili_go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testChunkSize 测试时预上传接口返回的分片大小
+const testChunkSize = 256 * 1024
+
+// uploadServer 模拟预上传接口和 upos 分片上传
+type uploadServer struct {
+	mu         sync.Mutex
+	chunkSize  int
+	threads    int
+	chunkRetry int
+	partsize   string // 获取 upload_id 时的 partsize 参数
+	parts      map[int][]byte
+	inflight   int
+	maxFlight  int
+	failPart   int         // 总是失败的分片
+	flaky      map[int]int // 分片前n次失败
+	slow       map[int]int // 分片前n次在超时后才返回
+	expired    bool
+	puts       map[int]int
+	checked    []UploadPart
+}
+
+func newUploadServer() *uploadServer {
+	return &uploadServer{
+		chunkSize: testChunkSize,
+		threads:   3,
+		parts:     make(map[int][]byte),
+		flaky:     make(map[int]int),
+		slow:      make(map[int]int),
+		puts:      make(map[int]int),
+	}
+}
+
+func (s *uploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	switch {
+	case r.URL.Path == "/preupload":
+		s.mu.Lock()
+		_, _ = fmt.Fprintf(w, `{"OK":1,"auth":"auth","biz_id":1,"chunk_size":%d,"chunk_retry":%d,"chunk_retry_delay":0,`+
+			`"endpoint":"//upos-cs-upcdnbldsa.bilivideo.com","threads":%d,"timeout":600,"upos_uri":"upos://ugcfx/n1.mp4"}`,
+			s.chunkSize, s.chunkRetry, s.threads)
+		s.mu.Unlock()
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.mu.Lock()
+		s.partsize = query.Get("partsize")
+		s.mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"OK":1,"upload_id":"upload-id"}`)
+	case r.Method == http.MethodPut:
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		s.mu.Lock()
+		s.puts[number]++
+		expired, fail, slow := s.expired, number == s.failPart || s.flaky[number] > 0, s.slow[number] > 0
+		if s.flaky[number] > 0 {
+			s.flaky[number]--
+		}
+		if s.slow[number] > 0 {
+			s.slow[number]--
+		}
+		s.inflight++
+		if s.inflight > s.maxFlight {
+			s.maxFlight = s.inflight
+		}
+		s.mu.Unlock()
+
+		// 让分片请求重叠，以便统计并发数，失败的分片在其他分片完成后才返回
+		time.Sleep(20 * time.Millisecond)
+		if fail || slow {
+			time.Sleep(200 * time.Millisecond)
+		}
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.inflight--
+		if !fail && !expired {
+			s.parts[number] = body
+		}
+		s.mu.Unlock()
+
+		switch {
+		case expired:
+			w.WriteHeader(http.StatusNotFound)
+		case fail:
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			w.Header().Set("Etag", "etag-"+strconv.Itoa(number))
+			_, _ = fmt.Fprint(w, "MULTIPART_PUT_SUCCESS")
+		}
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		var body struct {
+			Parts []UploadPart `json:"parts"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		s.mu.Lock()
+		s.checked = body.Parts
+		s.mu.Unlock()
+		_, _ = fmt.Fprint(w, `{"OK":1}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// content 按分片序号拼接已上传的内容
+func (s *uploadServer) content() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var buf bytes.Buffer
+	for i := 1; i <= len(s.parts); i++ {
+		buf.Write(s.parts[i])
+	}
+
+	return buf.Bytes()
+}
+
+func testVideo(size int) []byte {
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	return content
+}
+
+func TestUploadVideoStream(t *testing.T) {
+	content := testVideo(5*testChunkSize + 123)
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name   string
+		upload func(c *Client) (*SubmitVideo, error)
+	}{
+		{name: "reader at", upload: func(c *Client) (*SubmitVideo, error) {
+			return c.UploadVideoReaderAt(context.Background(), "test.mp4", bytes.NewReader(content), int64(len(content)))
+		}},
+		{name: "stream", upload: func(c *Client) (*SubmitVideo, error) {
+			return c.UploadVideoStream(context.Background(), "test.mp4", io.MultiReader(bytes.NewReader(content)), int64(len(content)))
+		}},
+		{name: "stream with unknown size", upload: func(c *Client) (*SubmitVideo, error) {
+			return c.UploadVideoStream(context.Background(), "test.mp4", io.MultiReader(bytes.NewReader(content)), -1)
+		}},
+		{name: "stream with unknown size larger than a chunk", upload: func(c *Client) (*SubmitVideo, error) {
+			return c.UploadVideoStream(context.Background(), "test.mp4", io.MultiReader(bytes.NewReader(content)), -1,
+				WithChunkSize(testChunkSize), WithTempDir(tempDir))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newUploadServer()
+			client := newTestClient(t, server)
+
+			video, err := tt.upload(client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if video.Filename != "n1" || video.CID != 1 || len(server.checked) != 6 {
+				t.Errorf("video = %+v, checked = %v", video, server.checked)
+			}
+			if len(server.parts) != 6 || !bytes.Equal(server.content(), content) {
+				t.Errorf("uploaded %d parts, content equal = %v", len(server.parts), bytes.Equal(server.content(), content))
+			}
+			if server.maxFlight > server.threads {
+				t.Errorf("max concurrent parts = %d, want <= %d", server.maxFlight, server.threads)
+			}
+			if entries, _ := os.ReadDir(tempDir); len(entries) != 0 {
+				t.Errorf("temp files not removed: %v", entries)
+			}
+		})
+	}
+}
+
+func TestUploadVideoStreamError(t *testing.T) {
+	content := testVideo(2*testChunkSize + 1)
+
+	// 内容比声明的大小短
+	client := newTestClient(t, newUploadServer())
+	if _, err := client.UploadVideoStream(context.Background(), "test.mp4", bytes.NewReader(content), int64(len(content))+1); err == nil {
+		t.Error("short stream should return error")
+	}
+
+	// 分片上传失败
+	server := newUploadServer()
+	server.failPart = 2
+	client = newTestClient(t, server)
+	if _, err := client.UploadVideo("test.mp4", content); err == nil {
+		t.Error("failed part should return error")
+	}
+	if server.checked != nil {
+		t.Error("upload should not be checked after a failed part")
+	}
+}
+
+// testSessionKey 视频在会话存储中的key
+func testSessionKey(t *testing.T, filename string, content []byte) string {
+	t.Helper()
+
+	fingerprint, err := contentFingerprint(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return uploadSessionKey(filename, int64(len(content)), fingerprint)
+}
+
+func TestResumeUpload(t *testing.T) {
+	content := testVideo(4*testChunkSize + 1)
+	server := newUploadServer()
+	server.failPart = 5
+	store := NewFileUploadSessionStore(t.TempDir())
+	client := newTestClient(t, server, WithUploadSessionStore(store))
+
+	if _, err := client.ResumeUpload(context.Background(), "test.mp4", bytes.NewReader(content), int64(len(content))); !errors.Is(err, ErrUploadSessionNotFound) {
+		t.Fatalf("err = %v, want ErrUploadSessionNotFound", err)
+	}
+
+	if _, err := client.UploadVideo("test.mp4", content); err == nil {
+		t.Fatal("failed part should return error")
+	}
+	session, err := store.LoadUploadSession(testSessionKey(t, "test.mp4", content))
+	if err != nil || session == nil {
+		t.Fatalf("session = %v, err = %v", session, err)
+	}
+	if session.UploadID != "upload-id" || session.ChunkSize != testChunkSize || len(session.Parts) == 0 || len(session.Parts) >= 5 {
+		t.Fatalf("session = %+v", session)
+	}
+	for _, part := range session.Parts {
+		if part.ETag != "etag-"+strconv.Itoa(part.PartNumber) {
+			t.Errorf("part %d etag = %s", part.PartNumber, part.ETag)
+		}
+	}
+
+	// 文件名和大小相同但内容不同的视频不能继续上传
+	changed := append([]byte(nil), content...)
+	changed[len(changed)-1]++
+	if _, err := client.ResumeUpload(context.Background(), "test.mp4", bytes.NewReader(changed), int64(len(changed))); !errors.Is(err, ErrUploadSessionNotFound) {
+		t.Fatalf("err = %v, want ErrUploadSessionNotFound for changed content", err)
+	}
+
+	server.mu.Lock()
+	server.failPart = 0
+	server.puts = make(map[int]int)
+	server.mu.Unlock()
+
+	video, err := client.ResumeUpload(context.Background(), "test.mp4", bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if video.CID != 1 {
+		t.Errorf("video = %+v", video)
+	}
+	for _, part := range session.Parts {
+		if server.puts[part.PartNumber] != 0 {
+			t.Errorf("completed part %d uploaded again", part.PartNumber)
+		}
+	}
+	if server.puts[5] != 1 {
+		t.Errorf("missing part uploaded %d times, want 1", server.puts[5])
+	}
+	if !bytes.Equal(server.content(), content) || len(server.checked) != 5 {
+		t.Errorf("content equal = %v, checked parts = %v", bytes.Equal(server.content(), content), server.checked)
+	}
+	if session, _ := store.LoadUploadSession(testSessionKey(t, "test.mp4", content)); session != nil {
+		t.Error("session should be deleted after upload finished")
+	}
+}
+
+func TestResumeUploadExpired(t *testing.T) {
+	content := testVideo(2*testChunkSize + 1)
+	server := newUploadServer()
+	server.failPart = 2
+	store := NewFileUploadSessionStore(t.TempDir())
+	client := newTestClient(t, server, WithUploadSessionStore(store))
+
+	if _, err := client.UploadVideo("test.mp4", content); err == nil {
+		t.Fatal("failed part should return error")
+	}
+
+	server.mu.Lock()
+	server.expired = true
+	server.mu.Unlock()
+
+	_, err := client.ResumeUpload(context.Background(), "test.mp4", bytes.NewReader(content), int64(len(content)))
+	if !errors.Is(err, ErrUploadSessionExpired) {
+		t.Fatalf("err = %v, want ErrUploadSessionExpired", err)
+	}
+	if session, _ := store.LoadUploadSession(testSessionKey(t, "test.mp4", content)); session != nil {
+		t.Error("expired session should be deleted")
+	}
+}
+
+func TestUploadServerParams(t *testing.T) {
+	content := testVideo(10*testChunkSize + 1)
+
+	tests := []struct {
+		name      string
+		opts      []UploadOption
+		chunkSize int
+		threads   int
+	}{
+		{name: "server", chunkSize: testChunkSize, threads: 2},
+		{name: "override", opts: []UploadOption{WithChunkSize(testChunkSize / 2), WithUploadThreads(1)}, chunkSize: testChunkSize / 2, threads: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newUploadServer()
+			server.threads = 2
+			client := newTestClient(t, server)
+
+			if _, err := client.UploadVideoContext(context.Background(), "test.mp4", content, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if server.partsize != strconv.Itoa(tt.chunkSize) {
+				t.Errorf("partsize = %s, want %d", server.partsize, tt.chunkSize)
+			}
+			if want := (len(content) + tt.chunkSize - 1) / tt.chunkSize; len(server.parts) != want {
+				t.Errorf("parts = %d, want %d", len(server.parts), want)
+			}
+			if server.maxFlight > tt.threads {
+				t.Errorf("max concurrent parts = %d, want <= %d", server.maxFlight, tt.threads)
+			}
+			if !bytes.Equal(server.content(), content) {
+				t.Error("content not equal")
+			}
+		})
+	}
+}
+
+func TestUploadChunkRetry(t *testing.T) {
+	content := testVideo(3*testChunkSize + 1)
+
+	// 服务端返回 chunk_retry=2，分片2前两次失败
+	server := newUploadServer()
+	server.chunkRetry = 2
+	server.flaky[2] = 2
+	client := newTestClient(t, server)
+	if _, err := client.UploadVideo("test.mp4", content); err != nil {
+		t.Fatal(err)
+	}
+	if server.puts[2] != 3 || !bytes.Equal(server.content(), content) {
+		t.Errorf("part 2 uploaded %d times, want 3", server.puts[2])
+	}
+
+	// 覆盖为不重试
+	server = newUploadServer()
+	server.chunkRetry = 2
+	server.flaky[2] = 1
+	client = newTestClient(t, server)
+	if _, err := client.UploadVideoContext(context.Background(), "test.mp4", content, WithChunkRetry(0, 0)); err == nil {
+		t.Error("failed part should not be retried")
+	}
+
+	// 分片超时后重试
+	server = newUploadServer()
+	server.chunkRetry = 1
+	server.slow[1] = 1
+	client = newTestClient(t, server)
+	if _, err := client.UploadVideoContext(context.Background(), "test.mp4", content, WithChunkTimeout(100*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if server.puts[1] != 2 || !bytes.Equal(server.content(), content) {
+		t.Errorf("part 1 uploaded %d times, want 2", server.puts[1])
+	}
+}
+
+func TestUploadProgress(t *testing.T) {
+	content := testVideo(3*testChunkSize + 1)
+
+	server := newUploadServer()
+	client := newTestClient(t, server)
+
+	var reports []UploadProgress
+	_, err := client.UploadVideoContext(context.Background(), "test.mp4", content,
+		WithProgress(func(p UploadProgress) { reports = append(reports, p) }), WithProgressInterval(time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var completed []int
+	for i, p := range reports {
+		if i > 0 && p.SentBytes < reports[i-1].SentBytes {
+			t.Errorf("SentBytes decreased: %d -> %d", reports[i-1].SentBytes, p.SentBytes)
+		}
+		if p.Chunk != 0 {
+			completed = append(completed, p.Chunk)
+		}
+		if p.Speed < 0 || p.AverageSpeed < 0 || p.TotalBytes != int64(len(content)) || p.Chunks != 4 {
+			t.Errorf("invalid progress %+v", p)
+		}
+	}
+	if len(completed) != 4 {
+		t.Errorf("completed chunks = %v, want 4", completed)
+	}
+
+	last := reports[len(reports)-1]
+	if last.SentBytes != last.TotalBytes || last.CompletedChunks != last.Chunks || last.ETA != 0 {
+		t.Errorf("final progress = %+v", last)
+	}
+}
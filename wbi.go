@@ -0,0 +1,171 @@
+package bilibili_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kainhuck/bilibili-go/internal/net"
+	"github.com/kainhuck/bilibili-go/wbi"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WbiKey wbi 签名所需的 img_key 和 sub_key，每天北京时间0点更新
+type WbiKey struct {
+	ImgKey    string    `json:"img_key"`
+	SubKey    string    `json:"sub_key"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WbiKeyStorage AuthStorage 可以选择实现该接口，用于在重启后复用 wbi key
+type WbiKeyStorage interface {
+	// LoadWbiKey 加载 WbiKey，不存在时返回 nil
+	LoadWbiKey() (*WbiKey, error)
+
+	// SaveWbiKey 保存 WbiKey
+	SaveWbiKey(*WbiKey) error
+}
+
+// wbi key 按北京时间每天更新
+var wbiKeyLocation = time.FixedZone("CST", 8*60*60)
+
+// String imgKey + subKey
+func (k *WbiKey) String() string {
+	return k.ImgKey + k.SubKey
+}
+
+// expired 不完整或者已经跨天
+func (k *WbiKey) expired(now time.Time) bool {
+	if k == nil || len(k.ImgKey) != 32 || len(k.SubKey) != 32 {
+		return true
+	}
+
+	y1, m1, d1 := k.UpdatedAt.In(wbiKeyLocation).Date()
+	y2, m2, d2 := now.In(wbiKeyLocation).Date()
+
+	return y1 != y2 || m1 != m2 || d1 != d2
+}
+
+// fetchWbiKey 从导航栏接口获取 wbi key，未登录时接口返回 -101 但同样包含 wbi_img
+func (c *Client) fetchWbiKey() (*WbiKey, error) {
+	c.InvalidateCache("api.bilibili.com/x/web-interface/nav")
+
+	nav, err := c.GetNavigation()
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == CodeUnLogin {
+		nav = &NavigationResponse{}
+		err = json.Unmarshal(apiErr.RawData(), nav)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get wbi key: %w", err)
+	}
+
+	key := &WbiKey{
+		ImgKey:    wbi.KeyFromURL(nav.WBIImg.ImgURL),
+		SubKey:    wbi.KeyFromURL(nav.WBIImg.SubURL),
+		UpdatedAt: time.Now(),
+	}
+	if len(key.ImgKey) != 32 || len(key.SubKey) != 32 {
+		return nil, fmt.Errorf("get wbi key: invalid wbi_img %q %q", nav.WBIImg.ImgURL, nav.WBIImg.SubURL)
+	}
+
+	return key, nil
+}
+
+// getWbiKey 获取 wbi key，优先使用内存和存储中当天的 key
+func (c *Client) getWbiKey(ctx context.Context) (string, error) {
+	c.wbiMutex.Lock()
+	defer c.wbiMutex.Unlock()
+
+	now := time.Now()
+	if c.wbiKey.expired(now) {
+		if storage, ok := c.authStorage.(WbiKeyStorage); ok {
+			if key, err := storage.LoadWbiKey(); err == nil && key != nil {
+				c.wbiKey = key
+			}
+		}
+	}
+	if !c.wbiKey.expired(now) {
+		return c.wbiKey.String(), nil
+	}
+
+	return c.updateWbiKey(ctx)
+}
+
+// refreshWbiKey 重新获取 wbi key，如果在 signedAt 之后已经被其他请求更新过则直接使用
+func (c *Client) refreshWbiKey(ctx context.Context, signedAt int64) (string, error) {
+	c.wbiMutex.Lock()
+	defer c.wbiMutex.Unlock()
+
+	if !c.wbiKey.expired(time.Now()) && c.wbiKey.UpdatedAt.Unix() > signedAt {
+		return c.wbiKey.String(), nil
+	}
+
+	return c.updateWbiKey(ctx)
+}
+
+// updateWbiKey 获取并持久化 wbi key，需持有 wbiMutex
+func (c *Client) updateWbiKey(ctx context.Context) (string, error) {
+	key, err := c.fetchWbiKey()
+	if err != nil {
+		return "", err
+	}
+	c.wbiKey = key
+	c.log(ctx, SubsystemAuth, LevelDebug, "wbi key updated", KV("img_key", key.ImgKey), KV("sub_key", key.SubKey))
+
+	if storage, ok := c.authStorage.(WbiKeyStorage); ok {
+		if err := storage.SaveWbiKey(key); err != nil {
+			c.log(ctx, SubsystemAuth, LevelWarn, "SaveWbiKey failed", KV(FieldError, err))
+		}
+	}
+
+	return key.String(), nil
+}
+
+// wbiMiddleware 签名的请求返回 -352 或 -403 时，说明 wbi key 可能已经更新，重新获取 key 并签名后重试一次
+func (c *Client) wbiMiddleware() Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(request *http.Request) (*http.Response, error) {
+			resp, err := next(request)
+			if err != nil || !request.URL.Query().Has("w_rid") {
+				return resp, err
+			}
+
+			code, err := peekCode(resp)
+			if err != nil {
+				return nil, err
+			}
+			if code == nil || (*code != -352 && *code != -403) {
+				return resp, nil
+			}
+
+			signedAt, _ := strconv.ParseInt(request.URL.Query().Get("wts"), 10, 64)
+			wbiKey, err := c.refreshWbiKey(request.Context(), signedAt)
+			if err != nil {
+				c.log(request.Context(), SubsystemAuth, LevelWarn, "refresh wbi key failed", KV(FieldError, err))
+				return resp, nil
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+
+			query := request.URL.Query()
+			net.EncWbi(query, wbiKey)
+			resigned := request.Clone(request.Context())
+			resigned.URL.RawQuery = query.Encode()
+			if request.GetBody != nil {
+				if resigned.Body, err = request.GetBody(); err != nil {
+					return nil, err
+				}
+			}
+
+			endpoint := Endpoint(request.URL)
+			c.metrics.IncRetry(endpoint)
+			c.log(request.Context(), SubsystemHTTP, LevelDebug, "re-sign wbi request", KV(FieldEndpoint, endpoint), KV("code", *code))
+
+			return next(resigned)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+// Package wbi 实现 bilibili 接口的 wbi 签名，可以脱离 bilibili_go.Client 单独使用
+//
+//	signer, err := wbi.FetchSigner(ctx, http.DefaultClient)
+//	if err != nil {
+//		panic(err)
+//	}
+//
+//	params := url.Values{"mid": {"2"}}
+//	signer.Sign(params) // 添加 wts 和 w_rid
+//	uri := "https://api.bilibili.com/x/space/wbi/acc/info?" + params.Encode()
+package wbi
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NavURL 获取 wbi key 的导航栏接口
+const NavURL = "https://api.bilibili.com/x/web-interface/nav"
+
+var mixinKeyEncTab = []int{
+	46, 47, 18, 2, 53, 8, 23, 32, 15, 50, 10, 31, 58, 3, 45, 35, 27, 43, 5, 49,
+	33, 9, 42, 19, 29, 28, 14, 39, 12, 38, 41, 13, 37, 48, 7, 16, 24, 55, 40,
+	61, 26, 17, 0, 1, 60, 51, 30, 4, 22, 25, 54, 21, 56, 59, 6, 63, 57, 62, 11,
+	36, 20, 34, 44, 52,
+}
+
+// MixinKey 由 imgKey 和 subKey 生成签名使用的 mixin key
+func MixinKey(imgKey string, subKey string) (string, error) {
+	orig := imgKey + subKey
+	if len(orig) < len(mixinKeyEncTab) {
+		return "", fmt.Errorf("wbi: invalid key length %d, want %d", len(orig), len(mixinKeyEncTab))
+	}
+
+	var str strings.Builder
+	for _, v := range mixinKeyEncTab {
+		str.WriteByte(orig[v])
+	}
+
+	return str.String()[:32], nil
+}
+
+// KeyFromURL 从 wbi_img 的 img_url 或 sub_url 中取出 key，
+// 比如 https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png
+func KeyFromURL(u string) string {
+	name := path.Base(u)
+
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// FetchKeys 从导航栏接口获取 imgKey 和 subKey，未登录时同样可以获取
+func FetchKeys(ctx context.Context, client *http.Client) (imgKey string, subKey string, err error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, NavURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	request.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/70.0.3538.77 Safari/537.36")
+	request.Header.Set("Referer", "https://www.bilibili.com/")
+
+	resp, err := client.Do(request)
+	if err != nil {
+		return "", "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var nav struct {
+		Data struct {
+			WbiImg struct {
+				ImgURL string `json:"img_url"`
+				SubURL string `json:"sub_url"`
+			} `json:"wbi_img"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&nav); err != nil {
+		return "", "", err
+	}
+
+	imgKey, subKey = KeyFromURL(nav.Data.WbiImg.ImgURL), KeyFromURL(nav.Data.WbiImg.SubURL)
+	if imgKey == "" || subKey == "" || imgKey == "." || subKey == "." {
+		return "", "", errors.New("wbi: wbi_img not found in nav response")
+	}
+
+	return imgKey, subKey, nil
+}
+
+// Signer wbi 签名
+type Signer struct {
+	mixinKey string
+
+	// Now 返回签名使用的时间，为nil时使用 time.Now，测试时可以固定时间
+	Now func() time.Time
+}
+
+// NewSigner 使用 imgKey 和 subKey 创建签名
+func NewSigner(imgKey string, subKey string) (*Signer, error) {
+	mixinKey, err := MixinKey(imgKey, subKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signer{mixinKey: mixinKey}, nil
+}
+
+// FetchSigner 从导航栏接口获取 key 并创建签名，key 每天更新，需要定期重新获取
+func FetchSigner(ctx context.Context, client *http.Client) (*Signer, error) {
+	imgKey, subKey, err := FetchKeys(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSigner(imgKey, subKey)
+}
+
+// MixinKey 返回签名使用的 mixin key
+func (s *Signer) MixinKey() string {
+	return s.mixinKey
+}
+
+// Sign 为 params 添加 wts 和 w_rid，已有的 wts 和 w_rid 会被替换，
+// 参数值中的 !'()* 会被移除，多值参数会全部参与签名
+func (s *Signer) Sign(params url.Values) {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	params.Del("w_rid")
+	params.Set("wts", strconv.FormatInt(now().Unix(), 10))
+
+	for k, values := range params {
+		for i, v := range values {
+			values[i] = sanitize(v)
+		}
+		params[k] = values
+	}
+
+	// Encode 按 key 排序，空格需要与 encodeURIComponent 一样编码为 %20
+	query := strings.ReplaceAll(params.Encode(), "+", "%20")
+	hash := md5.Sum([]byte(query + s.mixinKey))
+	params.Set("w_rid", hex.EncodeToString(hash[:]))
+}
+
+// SignURL 对 rawURL 的查询参数签名并返回新的地址
+func (s *Signer) SignURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	s.Sign(query)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+var unwantedChars = strings.NewReplacer("!", "", "'", "", "(", "", ")", "", "*", "")
+
+func sanitize(s string) string {
+	return unwantedChars.Replace(s)
+}
@@ -0,0 +1,86 @@
+package wbi
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// 测试向量来自 https://github.com/SocialSisterYi/bilibili-API-collect/blob/master/docs/misc/sign/wbi.md
+const (
+	specImgKey = "7cd084941338484aae1ad9425b84077c"
+	specSubKey = "4932caff0ff746eab6f01bf08b70ac45"
+)
+
+func TestMixinKey(t *testing.T) {
+	got, err := MixinKey(specImgKey, specSubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ea1db124af3c7062474693fa704f4ff8"; got != want {
+		t.Errorf("MixinKey() = %s, want %s", got, want)
+	}
+
+	if _, err := MixinKey("", ""); err == nil {
+		t.Error("MixinKey() with empty keys should return error")
+	}
+}
+
+func TestSign(t *testing.T) {
+	signer, err := NewSigner(specImgKey, specSubKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer.Now = func() time.Time { return time.Unix(1702204169, 0) }
+
+	tests := []struct {
+		name   string
+		params url.Values
+		want   string
+	}{
+		{
+			name:   "spec",
+			params: url.Values{"foo": {"114"}, "bar": {"514"}, "zab": {"1919810"}},
+			want:   "bar=514&foo=114&w_rid=8f6f2b5b3d485fe1886cec6a0be8c5d4&wts=1702204169&zab=1919810",
+		},
+		{
+			name:   "re-sign replaces wts and w_rid",
+			params: url.Values{"foo": {"114"}, "bar": {"514"}, "zab": {"1919810"}, "wts": {"1"}, "w_rid": {"x"}},
+			want:   "bar=514&foo=114&w_rid=8f6f2b5b3d485fe1886cec6a0be8c5d4&wts=1702204169&zab=1919810",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer.Sign(tt.params)
+			if got := tt.params.Encode(); got != tt.want {
+				t.Errorf("Sign() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignValues(t *testing.T) {
+	signer, _ := NewSigner(specImgKey, specSubKey)
+	signer.Now = func() time.Time { return time.Unix(1702204169, 0) }
+
+	a := url.Values{"keyword": {"hello world!"}, "tag": {"a", "b"}}
+	b := url.Values{"keyword": {"hello world"}, "tag": {"a"}}
+	signer.Sign(a)
+	signer.Sign(b)
+
+	if a.Get("keyword") != "hello world" {
+		t.Errorf("keyword = %q, unwanted chars should be removed", a.Get("keyword"))
+	}
+	if len(a["tag"]) != 2 {
+		t.Errorf("tag = %v, multi-valued params should be kept", a["tag"])
+	}
+	if a.Get("w_rid") == b.Get("w_rid") {
+		t.Error("every value of multi-valued params should be signed")
+	}
+}
+
+func TestKeyFromURL(t *testing.T) {
+	if got := KeyFromURL("https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png"); got != specImgKey {
+		t.Errorf("KeyFromURL() = %s", got)
+	}
+}
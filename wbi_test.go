@@ -0,0 +1,64 @@
+package bilibili_go
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWbiKeyExpired(t *testing.T) {
+	updatedAt := time.Date(2023, 12, 10, 23, 30, 0, 0, wbiKeyLocation)
+	key := &WbiKey{ImgKey: "7cd084941338484aae1ad9425b84077c", SubKey: "4932caff0ff746eab6f01bf08b70ac45", UpdatedAt: updatedAt}
+
+	tests := []struct {
+		name string
+		key  *WbiKey
+		now  time.Time
+		want bool
+	}{
+		{name: "nil", key: nil, now: updatedAt, want: true},
+		{name: "incomplete", key: &WbiKey{ImgKey: key.ImgKey, UpdatedAt: updatedAt}, now: updatedAt, want: true},
+		{name: "same day", key: key, now: updatedAt.Add(20 * time.Minute), want: false},
+		{name: "same day in utc", key: key, now: time.Date(2023, 12, 10, 15, 59, 0, 0, time.UTC), want: false},
+		{name: "rollover", key: key, now: updatedAt.Add(40 * time.Minute), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key.expired(tt.now); got != tt.want {
+				t.Errorf("expired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWbiResign(t *testing.T) {
+	var navCalls, infoCalls int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/x/web-interface/nav", func(w http.ResponseWriter, r *http.Request) {
+		navCalls++
+		// 未登录时同样返回 wbi_img
+		_, _ = w.Write([]byte(`{"code":-101,"message":"账号未登录","data":{"isLogin":false,"wbi_img":{` +
+			`"img_url":"https://i0.hdslb.com/bfs/wbi/7cd084941338484aae1ad9425b84077c.png",` +
+			`"sub_url":"https://i0.hdslb.com/bfs/wbi/4932caff0ff746eab6f01bf08b70ac45.png"}}}`))
+	})
+	mux.HandleFunc("/x/space/wbi/acc/info", func(w http.ResponseWriter, r *http.Request) {
+		infoCalls++
+		if infoCalls == 1 || r.URL.Query().Get("w_rid") == "" {
+			_, _ = w.Write([]byte(`{"code":-352,"message":"风控校验失败"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"code":0,"data":{"mid":2,"name":"test"}}`))
+	})
+	client := newTestClient(t, mux)
+
+	info, err := client.GetUserInfo(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "test" {
+		t.Errorf("name = %q", info.Name)
+	}
+	if navCalls != 2 || infoCalls != 2 {
+		t.Errorf("nav calls = %d, info calls = %d, want 2 and 2", navCalls, infoCalls)
+	}
+}